@@ -0,0 +1,114 @@
+package radix
+
+import (
+	"net/netip"
+)
+
+//
+// Route summarization.
+//
+// The bit oriented tree is walked from the bottom, and two sibling prefixes
+// which fill both halves of their parent prefix are merged into the parent.
+//
+//   10.0.0.0/25 + 10.0.0.128/25 => 10.0.0.0/24
+//   10.0.0.0/24 + 10.0.1.0/24   => 10.0.0.0/23
+//
+// The prefixes covered by a shorter prefix with the equal value are removed,
+// because the longest match returns the same value without them.
+//
+//   10.0.0.0/8 gig1 + 10.1.0.0/16 gig1 => 10.0.0.0/8 gig1
+//
+// When values are compared, the result gives the same longest match results as the original table.
+//
+
+// bitEntry definition, aggregated prefix of the bit oriented tree
+type bitEntry[V any] struct {
+	key   []byte
+	bits  int
+	value V
+}
+
+// aggregateBits() returns the aggregated entries under n, in the same order as walkBits().
+// inherited is the value of the longest match from above n, or nil.
+// top is true if the first entry is the prefix of n itself.
+func aggregateBits[V any](n *bitNode[V], inherited *V, eq func(a, b V) bool) (entries []bitEntry[V], top bool) {
+	var own *V
+	if n.hasValue && (inherited == nil || !eq(*inherited, n.value)) {
+		own = &n.value
+	}
+
+	effective := inherited
+	if own != nil {
+		effective = own
+	}
+
+	var results [2][]bitEntry[V]
+	var tops [2]bool
+	for i, c := range n.children {
+		if c != nil {
+			results[i], tops[i] = aggregateBits(c, effective, eq)
+		}
+	}
+
+	// both halves of n are filled by the children
+	full := true
+	for i, c := range n.children {
+		if c == nil || c.bits != n.bits+1 || !tops[i] {
+			full = false
+		}
+	}
+
+	if full {
+		v0 := results[0][0].value
+		v1 := results[1][0].value
+		if eq(v0, v1) {
+			// merge the children into n
+			results[0] = results[0][1:]
+			results[1] = results[1][1:]
+			own = &v0
+			if inherited != nil && eq(*inherited, v0) {
+				own = nil
+			}
+		} else if own != nil {
+			// the value of n is never used by the longest match
+			own = nil
+			for i := range results {
+				if inherited != nil && eq(*inherited, results[i][0].value) {
+					results[i] = results[i][1:]
+				}
+			}
+		}
+	}
+
+	if own != nil {
+		entries = append(entries, bitEntry[V]{key: n.key, bits: n.bits, value: *own})
+	}
+	entries = append(entries, results[0]...)
+	entries = append(entries, results[1]...)
+	return entries, own != nil
+}
+
+// Aggregate() returns the minimal set of prefixes which covers exactly the same addresses as the stored prefixes.
+// The values are ignored. IPv4 prefixes come first, then IPv6.
+func (t *PrefixTable[V]) Aggregate() []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	for _, e := range t.AggregateFunc(func(a, b V) bool { return true }) {
+		prefixes = append(prefixes, e.Prefix)
+	}
+	return prefixes
+}
+
+// AggregateFunc() is same as Aggregate(), but only merges the prefixes whose values are equal by eq,
+// for example, the routes to the same gateway.
+// The longest match on the result returns the same value as on the table for any address.
+func (t *PrefixTable[V]) AggregateFunc(eq func(a, b V) bool) []PrefixEntry[V] {
+	entries := []PrefixEntry[V]{}
+	for _, tree := range []*BitTree[V]{t.v4, t.v6} {
+		is4 := tree == t.v4
+		aggregated, _ := aggregateBits(tree.root, nil, eq)
+		for _, e := range aggregated {
+			entries = append(entries, PrefixEntry[V]{Prefix: bitsToPrefix(e.key, e.bits, is4), Value: e.value})
+		}
+	}
+	return entries
+}
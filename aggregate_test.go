@@ -0,0 +1,152 @@
+package radix
+
+import (
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		prefixes []string
+		expected []string
+	}{
+		{
+			[]string{"10.0.0.0/25", "10.0.0.128/25"},
+			[]string{"10.0.0.0/24"},
+		},
+		{
+			[]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/25", "10.0.3.128/25"},
+			[]string{"10.0.0.0/22"},
+		},
+		{
+			[]string{"10.0.0.0/8", "10.1.0.0/16", "10.2.3.0/24", "11.0.0.0/8"},
+			[]string{"10.0.0.0/7"},
+		},
+		{
+			[]string{"10.0.0.0/24", "10.0.2.0/24", "192.168.0.0/24"},
+			[]string{"10.0.0.0/24", "10.0.2.0/24", "192.168.0.0/24"},
+		},
+		{
+			[]string{"0.0.0.0/1", "128.0.0.0/1", "2001:db8::/33", "2001:db8:8000::/33"},
+			[]string{"0.0.0.0/0", "2001:db8::/32"},
+		},
+		{
+			[]string{},
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		r := NewPrefixTable[any]()
+		for _, p := range tt.prefixes {
+			r.Insert(netip.MustParsePrefix(p), nil)
+		}
+
+		got := []string{}
+		for _, p := range r.Aggregate() {
+			got = append(got, p.String())
+		}
+		if reflect.DeepEqual(got, tt.expected) == false {
+			t.Fatalf("prefixes=%v, expected=%v, got=%v", tt.prefixes, tt.expected, got)
+		}
+	}
+}
+
+func TestAggregateFunc(t *testing.T) {
+	routes := []struct {
+		prefix  string
+		gateway string
+	}{
+		{"10.0.0.0/8", "gig1"},
+		{"10.1.0.0/16", "gig1"}, // same as 10.0.0.0/8, removed
+		{"10.2.0.0/16", "gig2"},
+		{"10.2.0.0/24", "gig1"},
+		{"10.3.0.0/17", "gig3"}, // merged with 10.3.128.0/17
+		{"10.3.128.0/17", "gig3"},
+		{"192.168.0.0/25", "gig4"},
+		{"192.168.0.128/25", "gig5"}, // different gateway, not merged
+	}
+
+	expected := []string{
+		"10.0.0.0/8 gig1",
+		"10.2.0.0/16 gig2",
+		"10.2.0.0/24 gig1",
+		"10.3.0.0/16 gig3",
+		"192.168.0.0/25 gig4",
+		"192.168.0.128/25 gig5",
+	}
+
+	r := NewPrefixTable[string]()
+	for _, route := range routes {
+		r.Insert(netip.MustParsePrefix(route.prefix), route.gateway)
+	}
+
+	got := []string{}
+	for _, e := range r.AggregateFunc(func(a, b string) bool { return a == b }) {
+		got = append(got, e.Prefix.String()+" "+e.Value)
+	}
+	if reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+}
+
+func TestAggregateFuncRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 50; round++ {
+		r := NewPrefixTable[int]()
+		for i := 0; i < 200; i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), 0})
+			r.Insert(netip.PrefixFrom(addr, 14+rnd.Intn(11)).Masked(), rnd.Intn(3))
+		}
+
+		aggregated := NewPrefixTable[int]()
+		entries := r.AggregateFunc(func(a, b int) bool { return a == b })
+		for _, e := range entries {
+			if !aggregated.Insert(e.Prefix, e.Value) {
+				t.Fatalf("duplicated prefix %v", e.Prefix)
+			}
+		}
+		if aggregated.Len() > r.Len() {
+			t.Fatalf("expected length<=%v, got=%v", r.Len(), aggregated.Len())
+		}
+
+		// the longest match returns the same value for any address
+		for i := 0; i < 2000; i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), byte(rnd.Intn(256))})
+			_, v1, ok1 := r.Lookup(addr)
+			_, v2, ok2 := aggregated.Lookup(addr)
+			if ok1 != ok2 || v1 != v2 {
+				t.Fatalf("lookup %v: expected=%v %v, got=%v %v", addr, v1, ok1, v2, ok2)
+			}
+		}
+
+		// the set of the addresses is also the same
+		covered := NewPrefixTable[int]()
+		for _, p := range r.Aggregate() {
+			covered.Insert(p, 0)
+		}
+
+		// no prefix is covered by another, and no siblings are left
+		for _, p := range r.Aggregate() {
+			if len(covered.Covering(p)) != 1 {
+				t.Fatalf("%v is covered by %v", p, covered.Covering(p))
+			}
+			parent := netip.PrefixFrom(p.Addr(), p.Bits()-1).Masked()
+			if entries := covered.CoveredBy(parent); len(entries) == 2 && entries[0].Prefix.Bits() == p.Bits() && entries[1].Prefix.Bits() == p.Bits() {
+				t.Fatalf("siblings are not merged %v", entries)
+			}
+		}
+
+		for i := 0; i < 2000; i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), byte(rnd.Intn(256))})
+			_, _, ok1 := r.Lookup(addr)
+			_, _, ok2 := covered.Lookup(addr)
+			if ok1 != ok2 {
+				t.Fatalf("lookup %v: expected=%v, got=%v", addr, ok1, ok2)
+			}
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package radix
+
+import (
+	"math/bits"
+)
+
+//
+// BitTree is the bit oriented radix tree (Patricia trie).
+// Tree branches per rune, so the binary keys such as IP addresses have to be encoded as "0101..." strings.
+// BitTree branches per bit, and the key is a pair of []byte and its length in bits,
+// so it can store IP prefixes, MPLS labels, hash prefixes and so on without the string encoding.
+// Only the first bitLen bits of the key are used, the rest bits are ignored.
+//
+// Each node has up to two children, one for the next bit 0 and the other for 1.
+// The nodes which have only one child and no value are not created,
+// so the child may skip some bits from its parent.
+//
+//   root -+-(0)- [0000 1010 /8] --(0)- [0000 1010 0000 0000 /16]
+//         |
+//         +-(1)- [1100 0000 1010 1000 0000 0000 /24]
+//
+
+// BitTree definition
+type BitTree[V any] struct {
+	root *bitNode[V]
+	size int
+}
+
+// bitNode definition
+type bitNode[V any] struct {
+	key      []byte         // bits of the prefix until this node, the rest bits are zero
+	bits     int            // length of the prefix in bits, the child skips (child.bits - bits - 1) bits
+	hasValue bool           // true if this node stores a value, false for the branch node
+	value    V              // stored value
+	children [2]*bitNode[V] // child node for the next bit 0 and 1
+}
+
+// Callback function to pass when exploring the BitTree.
+// The key must not be modified. If true is returned, the tree search will stop at that point.
+//
+// The key is the storage of the node, not a copy.
+// The walks visit every node, such as the full BGP table of a million prefixes,
+// and copying each key would allocate as many times, so the callback copies the key if it keeps it.
+type BitWalkCallback[V any] func(key []byte, bitLen int, v V) bool
+
+// Constructor
+// NewBitTree() returns empty BitTree instance which stores values of type V
+func NewBitTree[V any]() *BitTree[V] {
+	return &BitTree[V]{
+		root: &bitNode[V]{},
+		size: 0,
+	}
+}
+
+// returns the i-th bit of the key, 0 or 1
+func bitAt(key []byte, i int) int {
+	return int(key[i>>3]>>(7-uint(i&7))) & 1
+}
+
+// returns the number of bits common to the given key1 and key2, up to max bits
+func commonBits(key1, key2 []byte, max int) int {
+	n := 0
+	for i := 0; n < max; i++ {
+		x := key1[i] ^ key2[i]
+		if x != 0 {
+			n += bits.LeadingZeros8(x)
+			break
+		}
+		n += 8
+	}
+	return minIntOf(n, max)
+}
+
+// returns the copy of the first bitLen bits of the key, the rest bits are cleared
+func maskBits(key []byte, bitLen int) []byte {
+	masked := make([]byte, (bitLen+7)/8)
+	copy(masked, key)
+	if r := bitLen % 8; r != 0 {
+		masked[len(masked)-1] &= ^byte(0xff >> r)
+	}
+	return masked
+}
+
+// returns true if the key has at least bitLen bits
+func validBits(key []byte, bitLen int) bool {
+	return bitLen >= 0 && bitLen <= len(key)*8
+}
+
+// returns the child node of n which is a prefix of the key
+func (n *bitNode[V]) matchChild(key []byte, bitLen int) *bitNode[V] {
+	c := n.children[bitAt(key, n.bits)]
+	if c == nil || c.bits > bitLen || commonBits(c.key, key, c.bits) != c.bits {
+		return nil
+	}
+	return c
+}
+
+// returns the number of children
+func (n *bitNode[V]) childLen() int {
+	num := 0
+	for _, c := range n.children {
+		if c != nil {
+			num++
+		}
+	}
+	return num
+}
+
+// replace the child old with the given child
+func (n *bitNode[V]) replaceChild(old, child *bitNode[V]) {
+	for i, c := range n.children {
+		if c == old {
+			n.children[i] = child
+		}
+	}
+}
+
+// returns the only child, or nil
+func (n *bitNode[V]) onlyChild() *bitNode[V] {
+	if n.children[0] != nil {
+		return n.children[0]
+	}
+	return n.children[1]
+}
+
+// Len() returns number of values stored in the BitTree
+func (t *BitTree[V]) Len() int {
+	return t.size
+}
+
+// Add a new value with the first bitLen bits of the key.
+// returns true if newly inserted.
+// returns false if update existing value, or bitLen is out of the key.
+func (t *BitTree[V]) Insert(key []byte, bitLen int, v V) (inserted bool) {
+	if !validBits(key, bitLen) {
+		return false
+	}
+
+	n := t.root
+	for {
+		// n has the key
+		if n.bits == bitLen {
+			inserted = !n.hasValue
+			n.hasValue = true
+			n.value = v
+			if inserted {
+				t.size++
+			}
+			return inserted
+		}
+
+		b := bitAt(key, n.bits)
+		c := n.children[b]
+
+		// if child node does not exist, spawn a new branch and exit
+		if c == nil {
+			n.children[b] = &bitNode[V]{
+				key:      maskBits(key, bitLen),
+				bits:     bitLen,
+				hasValue: true,
+				value:    v,
+			}
+			t.size++
+			return true
+		}
+
+		// the key is longer than the child, continue the search
+		common := commonBits(c.key, key, minIntOf(c.bits, bitLen))
+		if common == c.bits {
+			n = c
+			continue
+		}
+
+		leaf := &bitNode[V]{
+			key:      maskBits(key, bitLen),
+			bits:     bitLen,
+			hasValue: true,
+			value:    v,
+		}
+		t.size++
+
+		// the key is a prefix of the child
+		//   BEFORE: n -(b)- c
+		//   AFTER : n -(b)- leaf -- c
+		if common == bitLen {
+			leaf.children[bitAt(c.key, bitLen)] = c
+			n.children[b] = leaf
+			return true
+		}
+
+		// the key and the child diverge at the common bits
+		//   BEFORE: n -(b)- c
+		//   AFTER : n -(b)- branch -+- c
+		//                           +- leaf
+		branch := &bitNode[V]{
+			key:  maskBits(key, common),
+			bits: common,
+		}
+		branch.children[bitAt(c.key, common)] = c
+		branch.children[bitAt(key, common)] = leaf
+		n.children[b] = branch
+		return true
+	}
+}
+
+// returns the node which has exactly the first bitLen bits of the key, or nil
+func (t *BitTree[V]) find(key []byte, bitLen int) *bitNode[V] {
+	if !validBits(key, bitLen) {
+		return nil
+	}
+
+	n := t.root
+	for n != nil && n.bits < bitLen {
+		n = n.matchChild(key, bitLen)
+	}
+	return n
+}
+
+// If there is a value for the first bitLen bits of the key, it will be returned,
+// otherwise zero value and false will be returned.
+func (t *BitTree[V]) Get(key []byte, bitLen int) (V, bool) {
+	if n := t.find(key, bitLen); n != nil && n.hasValue {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete the value for the first bitLen bits of the key and returns it and true.
+// If not found, returns zero value and false.
+func (t *BitTree[V]) Delete(key []byte, bitLen int) (value V, deleted bool) {
+	if !validBits(key, bitLen) {
+		return value, false
+	}
+
+	var parent, grand *bitNode[V]
+	n := t.root
+	for n != nil && n.bits < bitLen {
+		grand, parent = parent, n
+		n = n.matchChild(key, bitLen)
+	}
+	if n == nil || !n.hasValue {
+		return value, false
+	}
+
+	value = n.value
+	var zero V
+	n.value = zero
+	n.hasValue = false
+	t.size--
+
+	if n == t.root {
+		return value, true
+	}
+
+	switch n.childLen() {
+	case 0:
+		// delete n, and if parent becomes a branch with one child, delete parent too
+		parent.replaceChild(n, nil)
+		if parent != t.root && !parent.hasValue && parent.childLen() == 1 {
+			grand.replaceChild(parent, parent.onlyChild())
+		}
+	case 1:
+		// n is no longer needed, connect its child to parent
+		parent.replaceChild(n, n.onlyChild())
+	}
+
+	return value, true
+}
+
+// Returns the longest stored prefix of the first bitLen bits of the key, its length in bits and its value.
+// The returned key is a copy, the caller may modify it.
+func (t *BitTree[V]) LongestMatch(key []byte, bitLen int) ([]byte, int, V, bool) {
+	if n := t.longestMatch(key, bitLen); n != nil {
+		return maskBits(n.key, n.bits), n.bits, n.value, true
+	}
+	var zero V
+	return nil, 0, zero, false
+}
+
+// returns the node with value which is the longest prefix of the first bitLen bits of the key
+func (t *BitTree[V]) longestMatch(key []byte, bitLen int) *bitNode[V] {
+	if !validBits(key, bitLen) {
+		return nil
+	}
+
+	var last *bitNode[V]
+	n := t.root
+	for n != nil {
+		if n.hasValue {
+			last = n
+		}
+		if n.bits == bitLen {
+			break
+		}
+		n = n.matchChild(key, bitLen)
+	}
+	return last
+}
+
+// Follow the tree from the root node and execute the callback function when you find the value.
+// The shorter prefix comes first, then the prefixes starting with bit 0, then 1.
+func (t *BitTree[V]) Walk(fn BitWalkCallback[V]) {
+	walkBits(t.root, fn)
+}
+
+// Walk only the values whose key starts with the first bitLen bits of the given key, same order as Walk()
+func (t *BitTree[V]) WalkPrefix(key []byte, bitLen int, fn BitWalkCallback[V]) {
+	if !validBits(key, bitLen) {
+		return
+	}
+
+	// find the shallowest node under the prefix
+	n := t.root
+	for n.bits < bitLen {
+		c := n.children[bitAt(key, n.bits)]
+		if c == nil {
+			return
+		}
+		if commonBits(c.key, key, minIntOf(c.bits, bitLen)) < minIntOf(c.bits, bitLen) {
+			return
+		}
+		n = c
+	}
+	walkBits(n, fn)
+}
+
+// Walk the values whose key is a prefix of the first bitLen bits of the given key, from the shortest to the longest.
+// These are the nodes that LongestMatch() passes through, the last one is the longest match.
+func (t *BitTree[V]) WalkPath(key []byte, bitLen int, fn BitWalkCallback[V]) {
+	if !validBits(key, bitLen) {
+		return
+	}
+
+	n := t.root
+	for n != nil {
+		if n.hasValue {
+			if fn(n.key, n.bits, n.value) {
+				return
+			}
+		}
+		if n.bits == bitLen {
+			return
+		}
+		n = n.matchChild(key, bitLen)
+	}
+}
+
+func walkBits[V any](n *bitNode[V], fn BitWalkCallback[V]) bool {
+	if n.hasValue {
+		if fn(n.key, n.bits, n.value) {
+			return true
+		}
+	}
+	for _, c := range n.children {
+		if c != nil && walkBits(c, fn) {
+			return true
+		}
+	}
+	return false
+}
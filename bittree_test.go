@@ -0,0 +1,152 @@
+package radix
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBitTree(t *testing.T) {
+	keys := []struct {
+		key    []byte
+		bitLen int
+	}{
+		{[]byte{}, 0},
+		{[]byte{0x00}, 1},
+		{[]byte{0x80}, 1},
+		{[]byte{0xa0}, 3},
+		{[]byte{0xa5}, 8},
+		{[]byte{0xa5, 0xc0}, 10},
+		{[]byte{0xa5, 0xc0, 0x10}, 20}, // MPLS label 0xa5c01
+		{[]byte{0xa5, 0xff}, 16},
+	}
+
+	r := NewBitTree[int]()
+
+	for i, k := range keys {
+		if !r.Insert(k.key, k.bitLen, i) {
+			t.Fatalf("insert failed %x/%v", k.key, k.bitLen)
+		}
+	}
+
+	if r.Len() != len(keys) {
+		t.Fatalf("expected length=%v, got=%v", len(keys), r.Len())
+	}
+
+	// the bits after bitLen are ignored
+	if v, ok := r.Get([]byte{0xbf}, 3); !ok || v != 3 {
+		t.Fatalf("expected=%v, got=%v", 3, v)
+	}
+	if _, ok := r.Get([]byte{0xa5}, 4); ok {
+		t.Fatalf("unexpected match %x/%v", 0xa5, 4)
+	}
+
+	// bitLen out of the key
+	if r.Insert([]byte{0xff}, 9, -1) {
+		t.Fatalf("invalid key inserted")
+	}
+
+	tests := []struct {
+		key      []byte
+		bitLen   int
+		expected int
+		matched  int
+	}{
+		{[]byte{0x7f}, 8, 1, 1},
+		{[]byte{0xa5, 0xc0, 0x1f}, 24, 6, 20},
+		{[]byte{0xa5, 0xc0, 0x1f}, 19, 5, 10},
+		{[]byte{0xa5, 0x00}, 16, 4, 8},
+		{[]byte{0xbf}, 8, 3, 3},
+		{[]byte{0xff}, 8, 2, 1},
+		{[]byte{0xff}, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		_, bitLen, v, ok := r.LongestMatch(tt.key, tt.bitLen)
+		if !ok || v != tt.expected || bitLen != tt.matched {
+			t.Fatalf("key=%x/%v, expected=%v/%v, got=%v/%v", tt.key, tt.bitLen, tt.expected, tt.matched, v, bitLen)
+		}
+	}
+
+	// the returned key is a copy, modifying it does not break the tree
+	key, bitLen, _, _ := r.LongestMatch([]byte{0xa5, 0xc0, 0x1f}, 24)
+	for i := range key {
+		key[i] = 0
+	}
+	if _, got, v, ok := r.LongestMatch([]byte{0xa5, 0xc0, 0x1f}, 24); !ok || got != bitLen || v != 6 {
+		t.Fatalf("expected=%v/%v, got=%v/%v", 6, bitLen, v, got)
+	}
+
+	// prefixes starting with 101
+	got := []int{}
+	r.WalkPrefix([]byte{0xa0}, 3, func(key []byte, bitLen int, v int) bool {
+		got = append(got, v)
+		return false
+	})
+	if reflect.DeepEqual(got, []int{3, 4, 5, 6, 7}) == false {
+		t.Fatalf("expected=%v, got=%v", []int{3, 4, 5, 6, 7}, got)
+	}
+
+	// prefixes starting with 1010 0101 1
+	got = []int{}
+	r.WalkPrefix([]byte{0xa5, 0x80}, 9, func(key []byte, bitLen int, v int) bool {
+		got = append(got, v)
+		return false
+	})
+	if reflect.DeepEqual(got, []int{5, 6, 7}) == false {
+		t.Fatalf("expected=%v, got=%v", []int{5, 6, 7}, got)
+	}
+
+	// delete
+	for i, k := range keys {
+		v, ok := r.Delete(k.key, k.bitLen)
+		if !ok || v != i {
+			t.Fatalf("delete failed %x/%v", k.key, k.bitLen)
+		}
+		if _, ok := r.Get(k.key, k.bitLen); ok {
+			t.Fatalf("found after delete %x/%v", k.key, k.bitLen)
+		}
+	}
+
+	if r.Len() != 0 {
+		t.Fatalf("expected length=%v, got=%v", 0, r.Len())
+	}
+}
+
+func TestBitTreeHashPrefix(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	type entry struct {
+		key    [32]byte
+		bitLen int
+	}
+
+	r := NewBitTree[int]()
+	entries := []entry{}
+	for i := 0; i < 1000; i++ {
+		e := entry{sha256.Sum256([]byte{byte(i), byte(i >> 8)}), 4 + rnd.Intn(12)}
+		entries = append(entries, e)
+		r.Insert(e.key[:], e.bitLen, i)
+	}
+
+	// compare with the brute force longest match
+	for i := 0; i < 1000; i++ {
+		h := sha256.Sum256([]byte{byte(i), byte(i >> 8), 0})
+
+		expected := -1
+		for _, e := range entries {
+			if commonBits(h[:], e.key[:], e.bitLen) == e.bitLen && e.bitLen > expected {
+				expected = e.bitLen
+			}
+		}
+
+		_, bitLen, _, ok := r.LongestMatch(h[:], 256)
+		if !ok {
+			bitLen = -1
+		}
+		if bitLen != expected {
+			t.Fatalf("hash %x: expected=%v, got=%v", h, expected, bitLen)
+		}
+	}
+}
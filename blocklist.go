@@ -0,0 +1,244 @@
+package radix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+//
+// Blocklist is the domain filter for DNS, loaded from the hosts files and the adblock style lists.
+// The rules are stored in two DomainTrees, one for the blocked domains and the other for the allowed domains.
+// If the host name matches the allowlist, it is never blocked.
+//
+//   hosts file          0.0.0.0 ads.example.com        blocks ads.example.com only
+//   adblock list        ||example.com^                 blocks example.com and its subdomains
+//                       @@||cdn.example.com^           allows cdn.example.com and its subdomains
+//   allowlist           cdn.example.com                allows cdn.example.com only
+//                       *.cdn.example.com              allows the subdomains of cdn.example.com
+//
+// The loaders read the lines one by one, so the large lists are not kept in memory.
+// The malformed lines are skipped and returned as *LineError joined by errors.Join().
+//
+
+// BlockEntry definition, the rule and where it comes from
+type BlockEntry struct {
+	Pattern string // normalized pattern of DomainTree
+	Allow   bool   // true if the rule is in the allowlist
+	Source  string // name of the list, such as the file name
+	Line    int    // line number in the list
+}
+
+// LineError definition, the malformed line in the list
+type LineError struct {
+	Source string
+	Line   int
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.Source, e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// host names in the hosts file which are not the targets of the filter
+var hostsLocalNames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+// Blocklist definition
+type Blocklist struct {
+	block *DomainTree[BlockEntry]
+	allow *DomainTree[BlockEntry]
+}
+
+// Constructor
+// NewBlocklist() returns empty Blocklist
+func NewBlocklist() *Blocklist {
+	return &Blocklist{
+		block: NewDomainTree[BlockEntry](),
+		allow: NewDomainTree[BlockEntry](),
+	}
+}
+
+// Len() returns number of the block rules
+func (b *Blocklist) Len() int {
+	return b.block.Len()
+}
+
+// AllowLen() returns number of the allow rules
+func (b *Blocklist) AllowLen() int {
+	return b.allow.Len()
+}
+
+// Block() adds the pattern of DomainTree to the blocklist.
+// If the same pattern already exists, the first one is kept and false is returned.
+func (b *Blocklist) Block(pattern, source string, line int) (bool, error) {
+	return addBlockEntry(b.block, pattern, BlockEntry{Source: source, Line: line})
+}
+
+// Allow() adds the pattern of DomainTree to the allowlist.
+// If the same pattern already exists, the first one is kept and false is returned.
+func (b *Blocklist) Allow(pattern, source string, line int) (bool, error) {
+	return addBlockEntry(b.allow, pattern, BlockEntry{Allow: true, Source: source, Line: line})
+}
+
+func addBlockEntry(tree *DomainTree[BlockEntry], pattern string, e BlockEntry) (bool, error) {
+	name, wildcard, err := parseDomainPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+	e.Pattern = domainPattern(name, wildcard)
+	if _, ok := tree.Get(e.Pattern); ok {
+		return false, nil
+	}
+	return tree.Insert(e.Pattern, e)
+}
+
+// Blocked() returns true if the host name matches the blocklist and does not match the allowlist.
+// The returned entry is the rule which decides the result,
+// the allow rule if allowed, the block rule if blocked, or zero BlockEntry if no rule matches.
+func (b *Blocklist) Blocked(host string) (BlockEntry, bool) {
+	if _, e, ok := b.allow.Match(host); ok {
+		return e, false
+	}
+	if _, e, ok := b.block.Match(host); ok {
+		return e, true
+	}
+	return BlockEntry{}, false
+}
+
+// scanLines() calls the function for each line with the line number.
+// The errors returned by the function are collected as *LineError.
+func scanLines(r io.Reader, source string, fn func(line string, lineNum int) (int, error)) (int, error) {
+	errs := []error{}
+	added := 0
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		n, err := fn(strings.TrimSpace(scanner.Text()), lineNum)
+		if err != nil {
+			errs = append(errs, &LineError{Source: source, Line: lineNum, Err: err})
+		}
+		added += n
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return added, errors.Join(errs...)
+}
+
+// returns 1 if inserted, otherwise 0
+func countInserted(inserted bool, err error) (int, error) {
+	if inserted {
+		return 1, err
+	}
+	return 0, err
+}
+
+// LoadHosts() reads the hosts file and blocks the host names, "0.0.0.0 ads.example.com tracker.example.com".
+// The address is ignored, and the local names such as "localhost" are skipped.
+// returns number of the added rules, and the malformed lines as the error.
+func (b *Blocklist) LoadHosts(r io.Reader, source string) (int, error) {
+	return scanLines(r, source, func(line string, lineNum int) (int, error) {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return 0, nil
+		}
+
+		// the address may have the zone, "fe80::1%lo0"
+		if _, err := netip.ParseAddr(fields[0]); err != nil {
+			return 0, fmt.Errorf("invalid address %q", fields[0])
+		}
+		if len(fields) == 1 {
+			return 0, fmt.Errorf("missing host name")
+		}
+
+		added := 0
+		var errs []error
+		for _, host := range fields[1:] {
+			if hostsLocalNames[strings.ToLower(host)] {
+				continue
+			}
+			n, err := countInserted(b.Block(host, source, lineNum))
+			if err != nil {
+				errs = append(errs, err)
+			}
+			added += n
+		}
+		return added, errors.Join(errs...)
+	})
+}
+
+// LoadAdblock() reads the adblock style list.
+// "||example.com^" blocks example.com and its subdomains, "@@||example.com^" allows them.
+// The lines starting with '!' or '#' are the comments, and the header such as "[Adblock Plus 2.0]" is skipped.
+// The other rules such as the URL patterns, the options and the element hiding rules are reported as the error.
+// returns number of the added rules, and the malformed lines as the error.
+func (b *Blocklist) LoadAdblock(r io.Reader, source string) (int, error) {
+	return scanLines(r, source, func(line string, lineNum int) (int, error) {
+		if line == "" || line[0] == '!' || (line[0] == '#' && !strings.HasPrefix(line, "##")) || line[0] == '[' {
+			return 0, nil
+		}
+
+		add := b.Block
+		rule := line
+		if rest, ok := strings.CutPrefix(rule, "@@"); ok {
+			add, rule = b.Allow, rest
+		}
+		domain, ok := strings.CutPrefix(rule, "||")
+		if ok {
+			domain, ok = strings.CutSuffix(domain, "^")
+		}
+		if !ok || strings.ContainsAny(domain, "/*^$|") {
+			return 0, fmt.Errorf("unsupported rule %q", line)
+		}
+
+		n, err := countInserted(add(domain, source, lineNum))
+		if err != nil {
+			return n, err
+		}
+		m, err := countInserted(add("*."+domain, source, lineNum))
+		return n + m, err
+	})
+}
+
+// LoadAllowlist() reads the list of the patterns of DomainTree, one per line, and allows them.
+// The text after '#' is the comment.
+// returns number of the added rules, and the malformed lines as the error.
+func (b *Blocklist) LoadAllowlist(r io.Reader, source string) (int, error) {
+	return scanLines(r, source, func(line string, lineNum int) (int, error) {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			return 0, nil
+		}
+		if strings.ContainsAny(line, " \t") {
+			return 0, fmt.Errorf("unexpected space in %q", line)
+		}
+		return countInserted(b.Allow(line, source, lineNum))
+	})
+}
@@ -0,0 +1,112 @@
+package radix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBlocklist(t *testing.T) {
+	hosts := `# hosts file
+127.0.0.1 localhost
+::1 localhost ip6-localhost ip6-loopback
+0.0.0.0 0.0.0.0
+0.0.0.0 ads.example.com tracker.example.com # trackers
+0.0.0.0 ADS.example.com
+0.0.0.0
+ads.example.net
+0.0.0.0 bad..name good.example.org
+`
+	adblock := `[Adblock Plus 2.0]
+! Title: test list
+||doubleclick.net^
+||example.net^
+@@||cdn.example.net^
+||example.org/ads.js
+example.com##.banner
+||tracking.example.^$third-party
+`
+	allowlist := `# allowlist
+good.example.org
+*.safe.doubleclick.net  # the subdomains only
+bad entry
+`
+
+	b := NewBlocklist()
+
+	n, err := b.LoadHosts(strings.NewReader(hosts), "hosts")
+	if n != 3 {
+		t.Fatalf("expected=3, got=%v", n)
+	}
+	checkLineErrors(t, err, "hosts", []int{7, 8, 9})
+
+	n, err = b.LoadAdblock(strings.NewReader(adblock), "adblock")
+	if n != 6 {
+		t.Fatalf("expected=6, got=%v", n)
+	}
+	checkLineErrors(t, err, "adblock", []int{6, 7, 8})
+
+	n, err = b.LoadAllowlist(strings.NewReader(allowlist), "allowlist")
+	if n != 2 {
+		t.Fatalf("expected=2, got=%v", n)
+	}
+	checkLineErrors(t, err, "allowlist", []int{4})
+
+	if b.Len() != 7 || b.AllowLen() != 4 {
+		t.Fatalf("expected=7/4, got=%v/%v", b.Len(), b.AllowLen())
+	}
+
+	tests := []struct {
+		host     string
+		blocked  bool
+		expected BlockEntry
+	}{
+		{"ads.example.com", true, BlockEntry{Pattern: "ads.example.com", Source: "hosts", Line: 5}},
+		{"Tracker.Example.com.", true, BlockEntry{Pattern: "tracker.example.com", Source: "hosts", Line: 5}},
+		{"www.ads.example.com", false, BlockEntry{}},
+		{"localhost", false, BlockEntry{}},
+		{"doubleclick.net", true, BlockEntry{Pattern: "doubleclick.net", Source: "adblock", Line: 3}},
+		{"ad.doubleclick.net", true, BlockEntry{Pattern: "*.doubleclick.net", Source: "adblock", Line: 3}},
+		{"safe.doubleclick.net", true, BlockEntry{Pattern: "*.doubleclick.net", Source: "adblock", Line: 3}},
+		{"a.safe.doubleclick.net", false, BlockEntry{Pattern: "*.safe.doubleclick.net", Allow: true, Source: "allowlist", Line: 3}},
+		{"www.example.net", true, BlockEntry{Pattern: "*.example.net", Source: "adblock", Line: 4}},
+		{"cdn.example.net", false, BlockEntry{Pattern: "cdn.example.net", Allow: true, Source: "adblock", Line: 5}},
+		{"img.cdn.example.net", false, BlockEntry{Pattern: "*.cdn.example.net", Allow: true, Source: "adblock", Line: 5}},
+		{"good.example.org", false, BlockEntry{Pattern: "good.example.org", Allow: true, Source: "allowlist", Line: 2}},
+		{"example.org", false, BlockEntry{}},
+	}
+	for _, tt := range tests {
+		e, blocked := b.Blocked(tt.host)
+		if blocked != tt.blocked || e != tt.expected {
+			t.Fatalf("host=%v, expected=%v %+v, got=%v %+v", tt.host, tt.blocked, tt.expected, blocked, e)
+		}
+	}
+
+	// the first rule is kept
+	if added, _ := b.Block("ads.example.com", "other", 1); added {
+		t.Fatalf("expected not added")
+	}
+	if e, _ := b.Blocked("ads.example.com"); e.Source != "hosts" {
+		t.Fatalf("expected=hosts, got=%v", e.Source)
+	}
+}
+
+func checkLineErrors(t *testing.T, err error, source string, lines []int) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected errors at %v", lines)
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != len(lines) {
+		t.Fatalf("expected=%v errors, got=%v", len(lines), err)
+	}
+	for i, e := range errs {
+		var lineErr *LineError
+		if !errors.As(e, &lineErr) {
+			t.Fatalf("expected LineError, got=%v", e)
+		}
+		if lineErr.Source != source || lineErr.Line != lines[i] {
+			t.Fatalf("expected=%v:%v, got=%v", source, lines[i], lineErr)
+		}
+	}
+}
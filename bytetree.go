@@ -0,0 +1,481 @@
+package radix
+
+import (
+	"sort"
+)
+
+//
+// ByteTree is the byte oriented variant of Tree.
+// Keys are handled as a sequence of bytes, not runes, so the search key can be
+// sliced from the given string as it is, without []rune(key) conversion.
+// Lookups do not allocate, and the prefixes of a node cost 1 byte per byte of the key.
+//
+// Edges are labeled by a single byte and kept sorted,
+// so the order of the keys is the same as comparing the strings in Go.
+//
+
+// ByteTree definition
+type ByteTree[V any] struct {
+	root *byteNode[V]
+	size int
+}
+
+// Constructor
+// NewByteTree() returns empty ByteTree instance which stores values of type V
+func NewByteTree[V any]() *ByteTree[V] {
+	return &ByteTree[V]{
+		root: &byteNode[V]{},
+		size: 0,
+	}
+}
+
+// Len() returns number of key-value-pairs stored in the ByteTree
+func (t *ByteTree[V]) Len() int {
+	return t.size
+}
+
+// Load() receive a map and store its contents in the ByteTree
+func (t *ByteTree[V]) Load(m map[string]V) {
+	for k, v := range m {
+		t.Insert(k, v)
+	}
+}
+
+// ToMap() is reverse of Load()
+// Convert key-value-pair to map and return
+func (t *ByteTree[V]) ToMap() map[string]V {
+	m := make(map[string]V, t.size)
+	t.Walk(func(k string, v V) bool {
+		m[k] = v
+		return false
+	})
+	return m
+}
+
+// byteNode definition
+type byteNode[V any] struct {
+	leaf     *TypedLeaf[V] // reference to a leaf node or nil
+	prefixes string        // Unique part excluding the intersection until this node
+	edges    []byteEdge[V] // slice of edge, always kept sorted
+}
+
+// byteEdge definition, byteEdge have single-byte labels that identify branches
+type byteEdge[V any] struct {
+	label byte         // single byte
+	node  *byteNode[V] // child node
+}
+
+// returns true if it has a reference to a leaf node
+func (n *byteNode[V]) isLeaf() bool {
+	return n.leaf != nil
+}
+
+// returns the index of the edge which has the specified label,
+// or the index where the edge should be inserted
+func (n *byteNode[V]) edgeIndex(label byte) int {
+	return sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].label >= label
+	})
+}
+
+// add the specified edge
+func (n *byteNode[V]) addEdge(e byteEdge[V]) {
+	// insert into the position that keeps the edges sorted
+	index := n.edgeIndex(e.label)
+	n.edges = append(n.edges, byteEdge[V]{})
+	copy(n.edges[index+1:], n.edges[index:])
+	n.edges[index] = e
+}
+
+// returns the child node beyond the edge of the specified label byte
+func (n *byteNode[V]) getChild(label byte) *byteNode[V] {
+	index := n.edgeIndex(label)
+	if index < len(n.edges) && n.edges[index].label == label {
+		return n.edges[index].node
+	}
+	return nil
+}
+
+// if there is an edge corresponding to the specified label byte,
+// change the reference to the child node and return true, otherwise return false
+func (n *byteNode[V]) updateEdge(label byte, node *byteNode[V]) bool {
+	index := n.edgeIndex(label)
+	if index < len(n.edges) && n.edges[index].label == label {
+		n.edges[index].node = node
+		return true
+	}
+	return false
+}
+
+// delete the edge with the specified label
+func (n *byteNode[V]) deleteEdge(label byte) bool {
+	index := n.edgeIndex(label)
+	if index < len(n.edges) && n.edges[index].label == label {
+		n.edges = n.edges[:index+copy(n.edges[index:], n.edges[index+1:])]
+		return true
+	}
+	return false
+}
+
+func (n *byteNode[V]) mergeChild() {
+	if len(n.edges) != 1 {
+		return
+	}
+	child := n.edges[0].node
+	n.prefixes = n.prefixes + child.prefixes
+	n.leaf = child.leaf
+	n.edges = child.edges
+}
+
+// returns the number of bytes common to the given key1 and key2
+func commonByteLength(key1, key2 string) int {
+	max := minIntOf(len(key1), len(key2))
+	var i int
+	for i = 0; i < max; i++ {
+		if key1[i] != key2[i] {
+			break
+		}
+	}
+	return i
+}
+
+func hasBytePrefix(s, prefixes string) bool {
+	return len(s) >= len(prefixes) && s[:len(prefixes)] == prefixes
+}
+
+// Add a new key-value pair to the tree.
+// returns true if newly inserted.
+// returns false if update existing key-value pair.
+func (t *ByteTree[V]) Insert(k string, v V) (inserted bool) {
+	// the search key is a substring of k, no conversion is needed
+	searches := k
+
+	var parent *byteNode[V]
+	n := t.root
+	for {
+
+		// The search key length is 0, which means that the existing node has that key.
+		if len(searches) == 0 {
+			if n.isLeaf() {
+				n.leaf.key = k
+				n.leaf.value = v
+				return false // false means overwrite existing node
+			}
+
+			// create a new leaf
+			n.leaf = &TypedLeaf[V]{
+				key:   k,
+				value: v,
+			}
+			t.size++
+			return true
+		}
+
+		// shift the parent node to n
+		parent = n
+
+		// shift n to the child node and proceed tree search
+		n = n.getChild(searches[0])
+
+		// if child node n does not exist, create an edge, spawn a new branch and exit
+		if n == nil {
+			parent.addEdge(byteEdge[V]{
+				label: searches[0],
+				node: &byteNode[V]{
+					leaf: &TypedLeaf[V]{
+						key:   k,
+						value: v,
+					},
+					prefixes: searches,
+				},
+			})
+			t.size++
+			return true
+		}
+
+		// find out the length of the common part between searches and the prefixes of the node
+		commonLen := commonByteLength(searches, n.prefixes)
+
+		// the search key may be longer than n.prefixes,
+		// so take out the unique part and continue the search.
+		if commonLen == len(n.prefixes) {
+			searches = searches[commonLen:]
+			continue
+		}
+
+		// split n into n1 and n2 and branch out from n1, same as Tree.Insert()
+		n1 := &byteNode[V]{}
+		n1.prefixes = n.prefixes[:commonLen]
+
+		n2 := n
+		n2.prefixes = n.prefixes[commonLen:]
+
+		parent.updateEdge(searches[0], n1)
+
+		n1.addEdge(byteEdge[V]{label: n2.prefixes[0], node: n2})
+
+		leaf := &TypedLeaf[V]{
+			key:   k,
+			value: v,
+		}
+		t.size++
+
+		prefixes := searches[commonLen:]
+
+		if len(prefixes) == 0 {
+			n1.leaf = leaf
+		} else {
+			n1.addEdge(byteEdge[V]{
+				label: prefixes[0],
+				node: &byteNode[V]{
+					leaf:     leaf,
+					prefixes: prefixes,
+				},
+			})
+		}
+
+		return true
+	}
+}
+
+// Delete key-value pair and returns its value and true.
+// If key not found, returns zero value and false.
+func (t *ByteTree[V]) Delete(key string) (value V, deleted bool) {
+	searches := key
+	var parent *byteNode[V]
+	var parentLabel byte
+	n := t.root
+	for {
+		if len(searches) == 0 {
+			if n.isLeaf() {
+				leaf := n.leaf
+				n.leaf = nil
+				t.size--
+
+				// If n has no edge, delete the edge from parent to n
+				if parent != nil && len(n.edges) == 0 {
+					parent.deleteEdge(parentLabel)
+				}
+
+				// If n has only one edge, mearge n and child
+				if n != t.root && len(n.edges) == 1 {
+					n.mergeChild()
+				}
+
+				// If parent has only one edge and parent has no leaf, merge parent and n
+				if parent != nil && parent != t.root && len(parent.edges) == 1 && !parent.isLeaf() {
+					parent.mergeChild()
+				}
+				return leaf.value, true
+			}
+			break
+		}
+
+		parent = n
+		parentLabel = searches[0]
+
+		n = n.getChild(searches[0])
+		if n == nil {
+			break
+		}
+
+		if hasBytePrefix(searches, n.prefixes) {
+			searches = searches[len(n.prefixes):]
+		} else {
+			break
+		}
+	}
+
+	return value, false
+}
+
+// If there is a key-value pair corresponding to given key, it will be returned,
+// otherwise zero value and false will be returned.
+func (t *ByteTree[V]) Get(key string) (V, bool) {
+	searches := key
+	n := t.root
+	for {
+		if len(searches) == 0 {
+			if n.isLeaf() {
+				return n.leaf.value, true
+			}
+			break
+		}
+
+		n = n.getChild(searches[0])
+		if n == nil {
+			break
+		}
+
+		if hasBytePrefix(searches, n.prefixes) {
+			searches = searches[len(n.prefixes):]
+		} else {
+			break
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Returns the closest key-value pair in a longest match rule
+func (t *ByteTree[V]) LongestMatch(key string) (string, V, bool) {
+	searches := key
+	var last *TypedLeaf[V]
+	n := t.root
+	for {
+		if n.isLeaf() {
+			last = n.leaf
+		}
+
+		if len(searches) == 0 {
+			break
+		}
+
+		n = n.getChild(searches[0])
+		if n == nil {
+			break
+		}
+
+		if hasBytePrefix(searches, n.prefixes) {
+			searches = searches[len(n.prefixes):]
+		} else {
+			break
+		}
+	}
+
+	if last != nil {
+		return last.key, last.value, true
+	}
+
+	var zero V
+	return "", zero, false
+}
+
+// Find all key-values starting with a given key
+func (t *ByteTree[V]) Collect(key string) []TypedLeaf[V] {
+	leafs := []TypedLeaf[V]{}
+
+	searches := key
+	var found *byteNode[V]
+	n := t.root
+	for {
+		if len(searches) == 0 {
+			found = n
+			break
+		}
+
+		n = n.getChild(searches[0])
+		if n == nil {
+			return leafs
+		}
+
+		if hasBytePrefix(searches, n.prefixes) {
+			searches = searches[len(n.prefixes):]
+			continue
+		}
+
+		if hasBytePrefix(n.prefixes, searches) {
+			found = n
+		}
+		break
+	}
+
+	if found == nil {
+		return leafs
+	}
+
+	walkBytes(found, func(k string, v V) bool {
+		leafs = append(leafs, TypedLeaf[V]{key: k, value: v})
+		return false
+	})
+
+	return leafs
+}
+
+func (t *ByteTree[V]) CollectKeys(key string) []string {
+	leafs := t.Collect(key)
+
+	keys := []string{}
+	for _, leaf := range leafs {
+		keys = append(keys, leaf.key)
+	}
+	return keys
+}
+
+// Find all values whose key starts with a given key, in order of the keys
+func (t *ByteTree[V]) CollectValues(key string) []V {
+	leafs := t.Collect(key)
+
+	values := make([]V, 0, len(leafs))
+	for _, leaf := range leafs {
+		values = append(values, leaf.value)
+	}
+	return values
+}
+
+// Find all key-values starting with a given key and return them as a map
+func (t *ByteTree[V]) CollectMap(key string) map[string]V {
+	leafs := t.Collect(key)
+
+	m := make(map[string]V, len(leafs))
+	for _, leaf := range leafs {
+		m[leaf.key] = leaf.value
+	}
+	return m
+}
+
+// The edges are sorted, so if you follow the younger edge, you will reach the top value.
+func (t *ByteTree[V]) Top() (string, V, bool) {
+	n := t.root
+	for {
+		if n.isLeaf() {
+			return n.leaf.key, n.leaf.value, true
+		}
+		if len(n.edges) > 0 {
+			n = n.edges[0].node
+		} else {
+			break
+		}
+	}
+	var zero V
+	return "", zero, false
+}
+
+// The edges are sorted, so if you follow the older edge, you will reach the last value.
+func (t *ByteTree[V]) Bottom() (string, V, bool) {
+	n := t.root
+	for {
+		if num := len(n.edges); num > 0 {
+			n = n.edges[num-1].node
+			continue
+		}
+		if n.isLeaf() {
+			return n.leaf.key, n.leaf.value, true
+		}
+		break
+	}
+	var zero V
+	return "", zero, false
+}
+
+// Follow the tree from the root node and execute the callback function when you find the leaf
+func (t *ByteTree[V]) Walk(fn TypedWalkCallback[V]) {
+	walkBytes(t.root, fn)
+}
+
+// Call the callback function when the leaf is reached, and end the search when it returns true
+func walkBytes[V any](n *byteNode[V], fn TypedWalkCallback[V]) bool {
+	if n.leaf != nil {
+		if fn(n.leaf.key, n.leaf.value) {
+			return true
+		}
+	}
+
+	for _, e := range n.edges {
+		if walkBytes(e.node, fn) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestByteTreeInsertDelete(t *testing.T) {
+	keys := []string{
+		"",
+		"あ",
+		"い",
+		"あい",
+		"あいう",
+		"romane",
+		"romanus",
+		"romulus",
+		"rubens",
+		"ruber",
+		"rubicon",
+		"rubicundus",
+		"\x00\x01",
+		"\x00\x01\xff",
+		"\xff",
+	}
+
+	r := NewByteTree[int]()
+
+	// insert
+	for i, key := range keys {
+		if !r.Insert(key, i) {
+			t.Fatalf("insert failed %q", key)
+		}
+	}
+
+	// update
+	for i, key := range keys {
+		if r.Insert(key, i) {
+			t.Fatalf("expected update, got insert %q", key)
+		}
+	}
+
+	// check length
+	if r.Len() != len(keys) {
+		t.Fatalf("expected length=%v, got=%v", len(keys), r.Len())
+	}
+
+	// check v == Get(k)
+	for i, key := range keys {
+		v, ok := r.Get(key)
+		if !ok || v != i {
+			t.Fatalf("key=%q, expected=%v, got=%v", key, i, v)
+		}
+	}
+
+	// delete keys not in the tree
+	for _, key := range keys {
+		if _, deleted := r.Delete(key + "_dummy"); deleted {
+			t.Fatalf("delete unexpected, %q", key+"_dummy")
+		}
+	}
+
+	// delete
+	for i, key := range keys {
+		v, ok := r.Delete(key)
+		if !ok || v != i {
+			t.Fatalf("delete failed %q", key)
+		}
+		if _, ok := r.Get(key); ok {
+			t.Fatalf("key found after delete %q", key)
+		}
+	}
+
+	// check length
+	if r.Len() != 0 {
+		t.Fatalf("expected length=%v, got=%v", 0, r.Len())
+	}
+}
+
+func TestByteTreeCollectKeys(t *testing.T) {
+	keys := []string{
+		"sea",
+		"sells",
+		"shells",
+		"shore",
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{"sea", "sells", "shells", "shore"}},
+		{"s", []string{"sea", "sells", "shells", "shore"}},
+		{"sh", []string{"shells", "shore"}},
+		{"she", []string{"shells"}},
+		{"sho", []string{"shore"}},
+		{"shore", []string{"shore"}},
+		{"shop", []string{}},
+	}
+
+	r := NewByteTree[any]()
+
+	for _, key := range keys {
+		r.Insert(key, nil)
+	}
+
+	for _, test := range tests {
+		ks := r.CollectKeys(test.input)
+		if reflect.DeepEqual(ks, test.expected) == false {
+			t.Fatalf("input: %v, expected: %v, got: %v", test.input, test.expected, ks)
+		}
+	}
+
+	top, _, _ := r.Top()
+	if top != "sea" {
+		t.Fatalf("expected top=%v, got=%v", "sea", top)
+	}
+
+	bottom, _, _ := r.Bottom()
+	if bottom != "shore" {
+		t.Fatalf("expected bottom=%v, got=%v", "shore", bottom)
+	}
+}
+
+func TestByteTreeLongestMatch(t *testing.T) {
+	keys := []string{
+		"",
+		"あ",
+		"あい",
+		"あいう",
+		"あいうえお",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a", ""},
+		{"あ", "あ"},
+		{"あいかわ", "あい"},
+		{"あいうえおかきくけこ", "あいうえお"},
+		{"あした", "あ"},
+	}
+
+	r := NewByteTree[string]()
+
+	for _, key := range keys {
+		r.Insert(key, key)
+	}
+
+	for _, tt := range tests {
+		m, v, ok := r.LongestMatch(tt.input)
+		if !ok {
+			t.Fatalf("key not found: %v", tt.input)
+		}
+		if m != tt.expected || v != tt.expected {
+			t.Fatalf("key=%v, expected=%v, got=%v", tt.input, tt.expected, m)
+		}
+	}
+}
+
+func TestByteTreeSameAsTree(t *testing.T) {
+	m := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		m[uuid()] = i
+	}
+
+	r1 := New()
+	r1.Load(m)
+
+	r2 := NewByteTree[any]()
+	r2.Load(m)
+
+	// both trees must walk the keys in the same order
+	keys1 := r1.CollectKeys("")
+	keys2 := r2.CollectKeys("")
+	if reflect.DeepEqual(keys1, keys2) == false {
+		t.Fatalf("walk order differs")
+	}
+
+	if reflect.DeepEqual(r2.ToMap(), m) == false {
+		t.Fatalf("expected=%v, got=%v", m, r2.ToMap())
+	}
+}
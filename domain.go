@@ -0,0 +1,284 @@
+package radix
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+//
+// DomainTree matches the host names against the domain patterns.
+//
+// The names are stored with the labels in reverse order, each label followed by a dot,
+// so that the tree branches on the label boundaries.
+//
+//   "teams.microsoft.com"   => "com.microsoft.teams."
+//   "*.microsoft.com"       => "com.microsoft."  (wildcard)
+//
+// "com.microsoft." is not a prefix of "com.microsoftonline.login.",
+// so "*.microsoft.com" never matches "login.microsoftonline.com".
+//
+// Match() prefers the exact entry, then the deepest wildcard entry.
+// The wildcard "*.example.com" matches "a.example.com" and "a.b.example.com", but not "example.com".
+// The pattern "*" matches any host name.
+//
+// The names are normalized to lower case without the trailing dot,
+// and the non-ASCII labels are converted to punycode, "bücher.example" => "xn--bcher-kva.example".
+//
+
+// entry of DomainTree, a name may have both the exact value and the wildcard value
+type domainEntry[V any] struct {
+	exact       V
+	wildcard    V
+	hasExact    bool
+	hasWildcard bool
+}
+
+// DomainTree definition
+type DomainTree[V any] struct {
+	tree *TypedTree[domainEntry[V]]
+	size int
+}
+
+// Constructor
+// NewDomainTree() returns empty DomainTree instance which stores values of type V
+func NewDomainTree[V any]() *DomainTree[V] {
+	return &DomainTree[V]{
+		tree: NewTree[domainEntry[V]](),
+		size: 0,
+	}
+}
+
+// NormalizeDomain() returns the domain name in lower case ASCII without the trailing dot.
+// The non-ASCII labels are converted to punycode with "xn--" prefix.
+// returns error if the name has an empty label, a too long label, an invalid character or invalid UTF-8.
+func NormalizeDomain(name string) (string, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return "", fmt.Errorf("radix: empty domain name")
+	}
+
+	// the invalid bytes must not be converted to U+FFFD by ToLower(), or the different names become the same
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("radix: invalid UTF-8 in %q", name)
+	}
+
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("radix: empty label in %q", name)
+		}
+		if !isASCII(label) {
+			encoded, err := punycodeEncode(label)
+			if err != nil {
+				return "", err
+			}
+			label = "xn--" + encoded
+		}
+		if len(label) > 63 {
+			return "", fmt.Errorf("radix: too long label %q", label)
+		}
+		for _, c := range []byte(label) {
+			if !isDomainChar(c) {
+				return "", fmt.Errorf("radix: invalid character %q in %q", c, name)
+			}
+		}
+		labels[i] = label
+	}
+
+	normalized := strings.Join(labels, ".")
+	if len(normalized) > 253 {
+		return "", fmt.Errorf("radix: too long domain name %q", name)
+	}
+	return normalized, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// letters, digits, hyphen and underscore, which is used in SRV and DKIM records
+func isDomainChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_'
+}
+
+// returns the normalized name and true if the pattern is the wildcard
+func parseDomainPattern(pattern string) (string, bool, error) {
+	if pattern == "*" {
+		return "", true, nil
+	}
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		name, err := NormalizeDomain(rest)
+		return name, true, err
+	}
+	name, err := NormalizeDomain(pattern)
+	return name, false, err
+}
+
+// returns the key of the tree, the reversed labels each followed by a dot.
+// The key of "" is "", which is used by the pattern "*".
+func domainKey(name string) string {
+	if name == "" {
+		return ""
+	}
+	labels := strings.Split(name, ".")
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i := len(labels) - 1; i >= 0; i-- {
+		b.WriteString(labels[i])
+		b.WriteByte('.')
+	}
+	return b.String()
+}
+
+// returns the domain name of the key
+func domainName(key string) string {
+	if key == "" {
+		return ""
+	}
+	name := domainKey(strings.TrimSuffix(key, "."))
+	return name[:len(name)-1]
+}
+
+// returns the pattern of the name
+func domainPattern(name string, wildcard bool) string {
+	switch {
+	case !wildcard:
+		return name
+	case name == "":
+		return "*"
+	default:
+		return "*." + name
+	}
+}
+
+// Len() returns number of patterns stored in the DomainTree
+func (t *DomainTree[V]) Len() int {
+	return t.size
+}
+
+// Insert() adds the pattern and its value.
+// The pattern is the domain name such as "example.com", or the wildcard such as "*.example.com" and "*".
+// returns true if newly inserted, false if update existing pattern.
+// returns error if the pattern is invalid.
+func (t *DomainTree[V]) Insert(pattern string, v V) (bool, error) {
+	name, wildcard, err := parseDomainPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	key := domainKey(name)
+	e, _ := t.tree.Get(key)
+	inserted := false
+	if wildcard {
+		inserted = !e.hasWildcard
+		e.wildcard, e.hasWildcard = v, true
+	} else {
+		inserted = !e.hasExact
+		e.exact, e.hasExact = v, true
+	}
+	t.tree.Insert(key, e)
+	if inserted {
+		t.size++
+	}
+	return inserted, nil
+}
+
+// Get() returns the value of the pattern, not the value matched with the host name.
+func (t *DomainTree[V]) Get(pattern string) (V, bool) {
+	var zero V
+	name, wildcard, err := parseDomainPattern(pattern)
+	if err != nil {
+		return zero, false
+	}
+
+	e, ok := t.tree.Get(domainKey(name))
+	switch {
+	case ok && wildcard && e.hasWildcard:
+		return e.wildcard, true
+	case ok && !wildcard && e.hasExact:
+		return e.exact, true
+	}
+	return zero, false
+}
+
+// Delete() deletes the pattern and returns its value and true.
+// If the pattern not found, returns zero value and false.
+func (t *DomainTree[V]) Delete(pattern string) (value V, deleted bool) {
+	name, wildcard, err := parseDomainPattern(pattern)
+	if err != nil {
+		return value, false
+	}
+
+	key := domainKey(name)
+	e, ok := t.tree.Get(key)
+	if !ok {
+		return value, false
+	}
+
+	var zero V
+	switch {
+	case wildcard && e.hasWildcard:
+		value, e.wildcard, e.hasWildcard = e.wildcard, zero, false
+	case !wildcard && e.hasExact:
+		value, e.exact, e.hasExact = e.exact, zero, false
+	default:
+		return value, false
+	}
+
+	if e.hasExact || e.hasWildcard {
+		t.tree.Insert(key, e)
+	} else {
+		t.tree.Delete(key)
+	}
+	t.size--
+	return value, true
+}
+
+// Match() returns the pattern which matches the host name and its value.
+// The exact pattern is preferred, then the deepest wildcard pattern.
+// If nothing matches or the host name is invalid, returns false.
+func (t *DomainTree[V]) Match(host string) (string, V, bool) {
+	var zero V
+	name, err := NormalizeDomain(host)
+	if err != nil {
+		return "", zero, false
+	}
+	key := domainKey(name)
+
+	var pattern string
+	var value V
+	found := false
+	t.tree.WalkPath(key, func(k string, e domainEntry[V]) bool {
+		if k == key && e.hasExact {
+			pattern, value, found = name, e.exact, true
+			return true
+		}
+		// the wildcard does not match the name itself
+		if len(k) < len(key) && e.hasWildcard {
+			pattern, value, found = domainPattern(domainName(k), true), e.wildcard, true
+		}
+		return false
+	})
+	return pattern, value, found
+}
+
+// Walk() calls the callback function for each pattern in the order of the reversed name,
+// the exact pattern comes before the wildcard pattern of the same name.
+func (t *DomainTree[V]) Walk(fn func(pattern string, v V) bool) {
+	t.tree.Walk(func(k string, e domainEntry[V]) bool {
+		name := domainName(k)
+		if e.hasExact && fn(name, e.exact) {
+			return true
+		}
+		if e.hasWildcard && fn(domainPattern(name, true), e.wildcard) {
+			return true
+		}
+		return false
+	})
+}
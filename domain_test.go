@@ -0,0 +1,124 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDomainTree(t *testing.T) {
+	patterns := []string{
+		"teams.microsoft.com",
+		"microsoft.com",
+		"*.teams.microsoft.com",
+		"*.microsoft.com",
+		"*.co.jp",
+		"Example.ORG.",
+		"*.bücher.example",
+		"日本語.jp",
+		"_dmarc.example.net",
+	}
+
+	r := NewDomainTree[string]()
+	for _, pattern := range patterns {
+		inserted, err := r.Insert(pattern, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !inserted {
+			t.Fatalf("expected inserted, pattern=%v", pattern)
+		}
+	}
+	if r.Len() != len(patterns) {
+		t.Fatalf("expected=%v, got=%v", len(patterns), r.Len())
+	}
+
+	tests := []struct {
+		host     string
+		pattern  string
+		expected string
+		found    bool
+	}{
+		{"teams.microsoft.com", "teams.microsoft.com", "teams.microsoft.com", true},
+		{"a.teams.microsoft.com", "*.teams.microsoft.com", "*.teams.microsoft.com", true},
+		{"a.b.teams.microsoft.com", "*.teams.microsoft.com", "*.teams.microsoft.com", true},
+		{"abc.microsoft.com", "*.microsoft.com", "*.microsoft.com", true},
+		{"MICROSOFT.com.", "microsoft.com", "microsoft.com", true},
+		{"login.microsoftonline.com", "", "", false},
+		{"microsoftonline.com", "", "", false},
+		{"co.jp", "", "", false},
+		{"example.co.jp", "*.co.jp", "*.co.jp", true},
+		{"example.org", "example.org", "Example.ORG.", true},
+		{"www.example.org", "", "", false},
+		{"www.Bücher.example", "*.xn--bcher-kva.example", "*.bücher.example", true},
+		{"www.xn--bcher-kva.example", "*.xn--bcher-kva.example", "*.bücher.example", true},
+		{"bücher.example", "", "", false},
+		{"xn--wgv71a119e.jp", "xn--wgv71a119e.jp", "日本語.jp", true},
+		{"_dmarc.example.net", "_dmarc.example.net", "_dmarc.example.net", true},
+		{"bad..name", "", "", false},
+		{"\xfe.com", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		pattern, v, found := r.Match(tt.host)
+		if found != tt.found || pattern != tt.pattern || v != tt.expected {
+			t.Fatalf("host=%v, expected=%v %v %v, got=%v %v %v", tt.host, tt.pattern, tt.expected, tt.found, pattern, v, found)
+		}
+	}
+
+	// the catch all pattern
+	r.Insert("*", "*")
+	if pattern, _, _ := r.Match("www.example.org"); pattern != "*" {
+		t.Fatalf("expected=*, got=%v", pattern)
+	}
+
+	// walk in the order of the reversed name
+	expected := []string{"*", "microsoft.com", "*.microsoft.com", "teams.microsoft.com", "*.teams.microsoft.com", "*.xn--bcher-kva.example", "*.co.jp", "xn--wgv71a119e.jp", "_dmarc.example.net", "example.org"}
+	got := []string{}
+	r.Walk(func(pattern string, v string) bool {
+		got = append(got, pattern)
+		return false
+	})
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	// delete the wildcard, and the exact pattern of the same name remains
+	if v, ok := r.Delete("*.microsoft.com"); !ok || v != "*.microsoft.com" {
+		t.Fatalf("expected=*.microsoft.com, got=%v", v)
+	}
+	if _, ok := r.Delete("*.microsoft.com"); ok {
+		t.Fatalf("deleted twice")
+	}
+	if pattern, _, _ := r.Match("abc.microsoft.com"); pattern != "*" {
+		t.Fatalf("expected=*, got=%v", pattern)
+	}
+	if v, ok := r.Get("microsoft.com"); !ok || v != "microsoft.com" {
+		t.Fatalf("expected=microsoft.com, got=%v", v)
+	}
+	if _, ok := r.Get("*.microsoft.com"); ok {
+		t.Fatalf("expected=false, got=true")
+	}
+	if r.Len() != len(patterns) {
+		t.Fatalf("expected=%v, got=%v", len(patterns), r.Len())
+	}
+
+	// update
+	if inserted, _ := r.Insert("MICROSOFT.COM", "updated"); inserted {
+		t.Fatalf("expected update")
+	}
+
+	invalids := []string{"", ".", "a..b", "*.", "a.*.b", "exa mple.com", "**.example.com", "\xff.com", "*.\xff.com"}
+	for _, pattern := range invalids {
+		if _, err := r.Insert(pattern, ""); err == nil {
+			t.Fatalf("expected error, pattern=%q", pattern)
+		}
+	}
+}
+
+func TestNormalizeDomainInvalidUTF8(t *testing.T) {
+	for _, name := range []string{"\xff.com", "\xfe.com", "a.b\xc3.com"} {
+		if normalized, err := NormalizeDomain(name); err == nil {
+			t.Fatalf("expected error, name=%q, got=%q", name, normalized)
+		}
+	}
+}
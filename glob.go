@@ -0,0 +1,248 @@
+package radix
+
+import (
+	"path"
+	"unicode/utf8"
+)
+
+//
+// Glob pattern matching over the keys, same syntax as path.Match().
+//
+//   *        matches any sequence of characters except '/'
+//   ?        matches any single character except '/'
+//   [a-z]    matches a character in the class except '/', [^a-z] or [!a-z] matches a character not in the class
+//   \c       matches the character c
+//
+// Unlike path.Match(), the negated class does not match '/' either,
+// so '/' in the key is matched only by '/' in the pattern, same as the shell.
+//
+// The pattern is compiled to the list of tokens, and the set of the positions in the pattern (NFA states)
+// is carried down the tree while following the prefixes of the nodes.
+// When the set becomes empty, no key below the node can match, so the branch is pruned.
+//
+//   Match("foo/*/bar?")
+//
+//   root --(f)-- [foo/] -+-(a)-- [a/bar] -+-(1)-- [1]    matches "foo/a/bar1"
+//                        |                +-(/)-- [/x]   pruned at '/', '?' does not match '/'
+//                        +-(x)-- [x/baz]                 pruned at 'z'
+//
+
+// kinds of the tokens
+const (
+	globLiteral = iota
+	globAny
+	globStar
+	globClass
+)
+
+// globRange definition, the range of the class
+type globRange struct {
+	lo, hi rune
+}
+
+// globToken definition
+type globToken struct {
+	kind   int
+	r      rune        // globLiteral
+	ranges []globRange // globClass
+	negate bool        // globClass
+}
+
+// returns true if the token matches the character, the star is handled by the caller
+func (tok *globToken) match(r rune) bool {
+	switch tok.kind {
+	case globLiteral:
+		return r == tok.r
+	case globAny:
+		return r != '/'
+	case globClass:
+		if r == '/' {
+			return false
+		}
+		matched := false
+		for _, rg := range tok.ranges {
+			if rg.lo <= r && r <= rg.hi {
+				matched = true
+				break
+			}
+		}
+		return matched != tok.negate
+	}
+	return false
+}
+
+// compileGlob() returns the tokens of the pattern, or path.ErrBadPattern
+func compileGlob(pattern string) ([]globToken, error) {
+	tokens := []globToken{}
+	for len(pattern) > 0 {
+		r, size := utf8.DecodeRuneInString(pattern)
+		pattern = pattern[size:]
+
+		switch r {
+		case '*':
+			// consecutive stars are same as one star
+			if len(tokens) == 0 || tokens[len(tokens)-1].kind != globStar {
+				tokens = append(tokens, globToken{kind: globStar})
+			}
+		case '?':
+			tokens = append(tokens, globToken{kind: globAny})
+		case '[':
+			tok, rest, err := compileGlobClass(pattern)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pattern = rest
+		case '\\':
+			if len(pattern) == 0 {
+				return nil, path.ErrBadPattern
+			}
+			r, size = utf8.DecodeRuneInString(pattern)
+			pattern = pattern[size:]
+			tokens = append(tokens, globToken{kind: globLiteral, r: r})
+		default:
+			tokens = append(tokens, globToken{kind: globLiteral, r: r})
+		}
+	}
+	return tokens, nil
+}
+
+// compileGlobClass() returns the class token and the rest of the pattern after ']'
+func compileGlobClass(pattern string) (globToken, string, error) {
+	tok := globToken{kind: globClass}
+	if len(pattern) > 0 && (pattern[0] == '^' || pattern[0] == '!') {
+		tok.negate = true
+		pattern = pattern[1:]
+	}
+
+	for {
+		if len(pattern) > 0 && pattern[0] == ']' && len(tok.ranges) > 0 {
+			return tok, pattern[1:], nil
+		}
+		lo, rest, err := globClassChar(pattern)
+		if err != nil {
+			return tok, "", err
+		}
+		hi := lo
+		if len(rest) > 0 && rest[0] == '-' {
+			if hi, rest, err = globClassChar(rest[1:]); err != nil {
+				return tok, "", err
+			}
+		}
+		tok.ranges = append(tok.ranges, globRange{lo: lo, hi: hi})
+		pattern = rest
+	}
+}
+
+// returns the character in the class, possibly escaped, same rule as path.Match()
+func globClassChar(pattern string) (rune, string, error) {
+	if len(pattern) == 0 || pattern[0] == '-' || pattern[0] == ']' {
+		return 0, "", path.ErrBadPattern
+	}
+	if pattern[0] == '\\' {
+		pattern = pattern[1:]
+		if len(pattern) == 0 {
+			return 0, "", path.ErrBadPattern
+		}
+	}
+	r, size := utf8.DecodeRuneInString(pattern)
+	if r == utf8.RuneError && size == 1 {
+		return 0, "", path.ErrBadPattern
+	}
+	return r, pattern[size:], nil
+}
+
+// adds the state and the states reachable without consuming a character, the star may match empty
+func globAdd(tokens []globToken, states []int, s int) []int {
+	for {
+		for _, x := range states {
+			if x == s {
+				return states
+			}
+		}
+		states = append(states, s)
+		if s == len(tokens) || tokens[s].kind != globStar {
+			return states
+		}
+		s++
+	}
+}
+
+// returns the states after consuming the character
+func globStep(tokens []globToken, states []int, r rune) []int {
+	next := []int{}
+	for _, s := range states {
+		if s == len(tokens) {
+			continue
+		}
+		tok := &tokens[s]
+		if tok.kind == globStar {
+			if r != '/' {
+				next = globAdd(tokens, next, s)
+			}
+			continue
+		}
+		if tok.match(r) {
+			next = globAdd(tokens, next, s+1)
+		}
+	}
+	return next
+}
+
+func walkGlob[V any](n *node[V], tokens []globToken, states []int, fn TypedWalkCallback[V]) bool {
+	for _, r := range n.prefixes {
+		states = globStep(tokens, states, r)
+		if len(states) == 0 {
+			return false
+		}
+	}
+
+	if n.isLeaf() {
+		for _, s := range states {
+			if s == len(tokens) {
+				if fn(n.leaf.key, n.leaf.value) {
+					return true
+				}
+				break
+			}
+		}
+	}
+
+	for _, e := range n.edges {
+		if walkGlob(e.node, tokens, states, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkMatch() calls the callback function for each key which matches the glob pattern, in order of the keys.
+// returns path.ErrBadPattern if the pattern is malformed.
+func (t *TypedTree[V]) WalkMatch(pattern string, fn TypedWalkCallback[V]) error {
+	tokens, err := compileGlob(pattern)
+	if err != nil {
+		return err
+	}
+	walkGlob(t.root, tokens, globAdd(tokens, nil, 0), fn)
+	return nil
+}
+
+// Find all key-values whose key matches the glob pattern, such as "foo/*/bar?"
+func (t *TypedTree[V]) Match(pattern string) ([]TypedLeaf[V], error) {
+	leafs := []TypedLeaf[V]{}
+	err := t.WalkMatch(pattern, func(k string, v V) bool {
+		leafs = append(leafs, TypedLeaf[V]{key: k, value: v})
+		return false
+	})
+	return leafs, err
+}
+
+// Find all keys which match the glob pattern
+func (t *TypedTree[V]) MatchKeys(pattern string) ([]string, error) {
+	keys := []string{}
+	err := t.WalkMatch(pattern, func(k string, v V) bool {
+		keys = append(keys, k)
+		return false
+	})
+	return keys, err
+}
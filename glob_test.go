@@ -0,0 +1,126 @@
+package radix
+
+import (
+	"math/rand"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	keys := []string{
+		"foo/a/bar1",
+		"foo/a/bar",
+		"foo/a/bar/x",
+		"foo/b/barz",
+		"foo/bc/bar2",
+		"foo//bar3",
+		"foo/x/baz",
+		"foobar",
+		"[a]",
+		"a*b",
+		"日本/東京",
+		"日本/大阪",
+	}
+
+	r := NewTree[int]()
+	for i, key := range keys {
+		r.Insert(key, i)
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"foo/*/bar?", []string{"foo//bar3", "foo/a/bar1", "foo/b/barz", "foo/bc/bar2"}},
+		{"foo/?/bar?", []string{"foo/a/bar1", "foo/b/barz"}},
+		{"foo/*/bar[0-9]", []string{"foo//bar3", "foo/a/bar1", "foo/bc/bar2"}},
+		{"foo/*/bar[^0-9]", []string{"foo/b/barz"}},
+		{"foo/*/bar[!0-9]", []string{"foo/b/barz"}},
+		{"foo/*", []string{}},
+		{"foo*", []string{"foobar"}},
+		{"*", []string{"[a]", "a*b", "foobar"}},
+		{"*/*/*", []string{"foo//bar3", "foo/a/bar", "foo/a/bar1", "foo/b/barz", "foo/bc/bar2", "foo/x/baz"}},
+		{"foo/a/bar", []string{"foo/a/bar"}},
+		{"\\[a\\]", []string{"[a]"}},
+		{"a\\*b", []string{"a*b"}},
+		{"日本/*", []string{"日本/大阪", "日本/東京"}},
+		{"日本/[東西]京", []string{"日本/東京"}},
+		{"nothing*", []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := r.MatchKeys(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(tt.expected)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("pattern=%v, expected=%v, got=%v", tt.pattern, tt.expected, got)
+		}
+	}
+
+	leafs, _ := r.Match("foo/?/bar")
+	if len(leafs) != 1 || leafs[0].Key() != "foo/a/bar" || leafs[0].Value() != 1 {
+		t.Fatalf("unexpected leafs %v", leafs)
+	}
+
+	invalids := []string{"[", "[]", "[a", "[a-", "[-a]", "[a-]", "\\", "foo[", "[\\"}
+	for _, pattern := range invalids {
+		if _, err := r.Match(pattern); err != path.ErrBadPattern {
+			t.Fatalf("pattern=%q, expected=%v, got=%v", pattern, path.ErrBadPattern, err)
+		}
+	}
+}
+
+func TestMatchRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	letters := []byte("ab/")
+	randomString := func(chars []byte, n int) string {
+		b := make([]byte, rnd.Intn(n))
+		for i := range b {
+			b[i] = chars[rnd.Intn(len(chars))]
+		}
+		return string(b)
+	}
+
+	r := NewTree[int]()
+	for i := 0; i < 1000; i++ {
+		r.Insert(randomString(letters, 8), i)
+	}
+	all := r.CollectKeys("")
+
+	patternChars := []byte("ab/*?[")
+	for i := 0; i < 1000; i++ {
+		pattern := randomString(patternChars, 6)
+		// make the classes valid
+		for j := 0; j < len(pattern); j++ {
+			if pattern[j] == '[' {
+				pattern = pattern[:j] + "[^b]" + pattern[j+1:]
+				j += 3
+			}
+		}
+
+		// the same pattern in regexp, no character but '/' matches '/'
+		expr := strings.NewReplacer("*", "[^/]*", "?", "[^/]", "[^b]", "[^b/]").Replace(pattern)
+		re := regexp.MustCompile("^" + expr + "$")
+
+		expected := []string{}
+		for _, key := range all {
+			if re.MatchString(key) {
+				expected = append(expected, key)
+			}
+		}
+
+		got, err := r.MatchKeys(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("pattern=%v, expected=%v, got=%v", pattern, expected, got)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package radix
+
+import (
+	"iter"
+)
+
+//
+// ImmutableTree is the persistent variant of Tree.
+// It is never modified once created, Insert() and Delete() return a new tree
+// which shares the unchanged nodes with the old one (path copying).
+// Only the nodes on the path from the root to the changed node are copied,
+// so readers holding the old tree keep seeing consistent data without any lock,
+// while writers publish new versions.
+//
+// Txn batches many mutations and Commit() publishes a new tree.
+// The nodes copied in a transaction are owned by the transaction,
+// so they are modified in place until Commit() is called.
+//
+//   BEFORE: root -(r)- [r] -+-(o)- [om]
+//                           +-(u)- [ubens]
+//
+//   Insert("rubicon")
+//
+//   AFTER : root'-(r)- [r]'-+-(o)- [om]      (shared with BEFORE)
+//                           +-(u)- [ub]' -+-(e)- [ens]
+//                                         +-(i)- [icon]
+//
+
+// ImmutableTree definition
+type ImmutableTree[V any] struct {
+	tree TypedTree[V] // read only, the nodes must not be modified
+}
+
+// Constructor
+// NewImmutableTree() returns empty ImmutableTree instance which stores values of type V
+func NewImmutableTree[V any]() *ImmutableTree[V] {
+	return &ImmutableTree[V]{
+		tree: TypedTree[V]{
+			root: &node[V]{},
+			size: 0,
+		},
+	}
+}
+
+// Len() returns number of key-value-pairs stored in the ImmutableTree
+func (t *ImmutableTree[V]) Len() int {
+	return t.tree.Len()
+}
+
+// ToMap() converts key-value-pair to map and return
+func (t *ImmutableTree[V]) ToMap() map[string]V {
+	return t.tree.ToMap()
+}
+
+// Txn() starts a new transaction based on this tree
+func (t *ImmutableTree[V]) Txn() *Txn[V] {
+	return &Txn[V]{
+		tree:     t.tree,
+		writable: make(map[*node[V]]struct{}),
+	}
+}
+
+// Insert() returns a new tree which has the key-value pair,
+// and true if newly inserted, false if update existing key-value pair.
+func (t *ImmutableTree[V]) Insert(k string, v V) (*ImmutableTree[V], bool) {
+	txn := t.Txn()
+	inserted := txn.Insert(k, v)
+	return txn.Commit(), inserted
+}
+
+// Delete() returns a new tree without the key-value pair, its value and true.
+// If key not found, returns the tree itself, zero value and false.
+func (t *ImmutableTree[V]) Delete(key string) (*ImmutableTree[V], V, bool) {
+	txn := t.Txn()
+	value, deleted := txn.Delete(key)
+	if !deleted {
+		return t, value, false
+	}
+	return txn.Commit(), value, true
+}
+
+// If there is a key-value pair corresponding to given key, it will be returned,
+// otherwise zero value and false will be returned.
+func (t *ImmutableTree[V]) Get(key string) (V, bool) {
+	return t.tree.Get(key)
+}
+
+// Returns the closest key-value pair in a longest match rule
+func (t *ImmutableTree[V]) LongestMatch(key string) (string, V, bool) {
+	return t.tree.LongestMatch(key)
+}
+
+// Returns the closest key-value pair in a shortest match rule
+func (t *ImmutableTree[V]) ShortestMatch(key string) (string, V, bool) {
+	return t.tree.ShortestMatch(key)
+}
+
+// Returns all key-value pairs whose key is a prefix of a given key, from the shortest to the longest
+func (t *ImmutableTree[V]) PrefixMatches(key string) []TypedLeaf[V] {
+	return t.tree.PrefixMatches(key)
+}
+
+// Find all key-values starting with a given key
+func (t *ImmutableTree[V]) Collect(key string) []TypedLeaf[V] {
+	return t.tree.Collect(key)
+}
+
+func (t *ImmutableTree[V]) CollectKeys(key string) []string {
+	return t.tree.CollectKeys(key)
+}
+
+func (t *ImmutableTree[V]) CollectValues(key string) []V {
+	return t.tree.CollectValues(key)
+}
+
+func (t *ImmutableTree[V]) CollectMap(key string) map[string]V {
+	return t.tree.CollectMap(key)
+}
+
+// Find all key-values whose key matches the glob pattern
+func (t *ImmutableTree[V]) Match(pattern string) ([]TypedLeaf[V], error) {
+	return t.tree.Match(pattern)
+}
+
+func (t *ImmutableTree[V]) MatchKeys(pattern string) ([]string, error) {
+	return t.tree.MatchKeys(pattern)
+}
+
+// Returns the first key-value pair of the tree
+func (t *ImmutableTree[V]) Top() (string, V, bool) {
+	return t.tree.Top()
+}
+
+// Returns the last key-value pair of the tree
+func (t *ImmutableTree[V]) Bottom() (string, V, bool) {
+	return t.tree.Bottom()
+}
+
+// Follow the tree from the root node and execute the callback function when you find the leaf
+func (t *ImmutableTree[V]) Walk(fn TypedWalkCallback[V]) {
+	t.tree.Walk(fn)
+}
+
+// Same as Walk(), but in descending order
+func (t *ImmutableTree[V]) WalkReverse(fn TypedWalkCallback[V]) {
+	t.tree.WalkReverse(fn)
+}
+
+// Walk only the key-value pairs starting with a given prefix, same order as Walk()
+func (t *ImmutableTree[V]) WalkPrefix(prefix string, fn TypedWalkCallback[V]) {
+	t.tree.WalkPrefix(prefix, fn)
+}
+
+// Walk the key-value pairs whose key is a prefix of a given key, from the shortest to the longest
+func (t *ImmutableTree[V]) WalkPath(key string, fn TypedWalkCallback[V]) {
+	t.tree.WalkPath(key, fn)
+}
+
+// Range() calls the callback function for each key-value pair where lo <= key < hi, in ascending order.
+// The tree is never modified, so the callback function may run as long as it needs without any lock.
+func (t *ImmutableTree[V]) Range(lo, hi string, fn TypedWalkCallback[V]) {
+	t.tree.Range(lo, hi, fn)
+}
+
+// RangeReverse() is same as Range(), but in descending order.
+func (t *ImmutableTree[V]) RangeReverse(lo, hi string, fn TypedWalkCallback[V]) {
+	t.tree.RangeReverse(lo, hi, fn)
+}
+
+// Iterator() returns a new unpositioned Iterator.
+// ImmutableTree is never modified, so the Iterator stays valid even after the newer versions are committed.
+func (t *ImmutableTree[V]) Iterator() *Iterator[V] {
+	return t.tree.Iterator()
+}
+
+// All() returns an iterator over all key-value pairs in ascending order
+func (t *ImmutableTree[V]) All() iter.Seq2[string, V] {
+	return t.tree.All()
+}
+
+// Backward() returns an iterator over all key-value pairs in descending order
+func (t *ImmutableTree[V]) Backward() iter.Seq2[string, V] {
+	return t.tree.Backward()
+}
+
+// WithPrefix() returns an iterator over the key-value pairs starting with a given prefix
+func (t *ImmutableTree[V]) WithPrefix(prefix string) iter.Seq2[string, V] {
+	return t.tree.WithPrefix(prefix)
+}
+
+// Between() returns an iterator over the key-value pairs where lo <= key < hi
+func (t *ImmutableTree[V]) Between(lo, hi string) iter.Seq2[string, V] {
+	return t.tree.Between(lo, hi)
+}
+
+// Keys() returns an iterator over all keys in ascending order
+func (t *ImmutableTree[V]) Keys() iter.Seq[string] {
+	return t.tree.Keys()
+}
+
+// Values() returns an iterator over all values in ascending order of the keys
+func (t *ImmutableTree[V]) Values() iter.Seq[V] {
+	return t.tree.Values()
+}
+
+// Txn definition
+// Txn is not safe for concurrent use, but the trees committed by Txn are.
+type Txn[V any] struct {
+	tree     TypedTree[V]
+	writable map[*node[V]]struct{} // nodes created in this transaction
+}
+
+// Len() returns number of key-value-pairs in the transaction
+func (txn *Txn[V]) Len() int {
+	return txn.tree.Len()
+}
+
+// Get() returns the value in the transaction, including uncommitted changes
+func (txn *Txn[V]) Get(key string) (V, bool) {
+	return txn.tree.Get(key)
+}
+
+// Commit() returns a new tree which has all the changes made in the transaction.
+// The transaction can be used continuously after Commit(),
+// the committed tree is not affected by the subsequent changes.
+func (txn *Txn[V]) Commit() *ImmutableTree[V] {
+	// the nodes are now shared with the committed tree, they must be copied again
+	txn.writable = make(map[*node[V]]struct{})
+	return &ImmutableTree[V]{tree: txn.tree}
+}
+
+// returns a new node owned by the transaction
+func (txn *Txn[V]) newNode(n *node[V]) *node[V] {
+	txn.writable[n] = struct{}{}
+	return n
+}
+
+// returns n itself if it is owned by the transaction, otherwise a copy of n
+func (txn *Txn[V]) writeNode(n *node[V]) *node[V] {
+	if _, ok := txn.writable[n]; ok {
+		return n
+	}
+	nc := &node[V]{
+		leaf:     n.leaf,
+		prefixes: n.prefixes,
+		edges:    append([]edge[V](nil), n.edges...),
+	}
+	return txn.newNode(nc)
+}
+
+// same as node.mergeChild(), but the prefixes and the edges are not shared with the child
+func (txn *Txn[V]) mergeChild(n *node[V]) {
+	if len(n.edges) != 1 {
+		return
+	}
+	child := n.edges[0].node
+	prefixes := make([]rune, 0, len(n.prefixes)+len(child.prefixes))
+	prefixes = append(prefixes, n.prefixes...)
+	n.prefixes = append(prefixes, child.prefixes...)
+	n.leaf = child.leaf
+	n.edges = append([]edge[V](nil), child.edges...)
+}
+
+// Add a new key-value pair to the transaction.
+// returns true if newly inserted.
+// returns false if update existing key-value pair.
+func (txn *Txn[V]) Insert(k string, v V) (inserted bool) {
+	// Search logic is same as Tree.Insert(), but copy the nodes on the path
+	searches := []rune(k)
+
+	n := txn.writeNode(txn.tree.root)
+	txn.tree.root = n
+	for {
+		if len(searches) == 0 {
+			// leaf may be shared with other trees, so replace it instead of update
+			inserted = !n.isLeaf()
+			n.leaf = &TypedLeaf[V]{
+				key:   k,
+				value: v,
+			}
+			if inserted {
+				txn.tree.size++
+			}
+			return inserted
+		}
+
+		parent := n
+		child := n.getChild(searches[0])
+
+		// if child node does not exist, create an edge, spawn a new branch and exit
+		if child == nil {
+			parent.addEdge(edge[V]{
+				label: searches[0],
+				node: txn.newNode(&node[V]{
+					leaf: &TypedLeaf[V]{
+						key:   k,
+						value: v,
+					},
+					prefixes: searches,
+				}),
+			})
+			txn.tree.size++
+			return true
+		}
+
+		commonLen := commonLength(searches, child.prefixes)
+
+		// copy the child and continue the search
+		if commonLen == len(child.prefixes) {
+			n = txn.writeNode(child)
+			parent.updateEdge(searches[0], n)
+			searches = searches[commonLen:]
+			continue
+		}
+
+		// split the child into n1 and n2 and branch out from n1
+		n1 := txn.newNode(&node[V]{
+			prefixes: child.prefixes[:commonLen:commonLen], // cap it to avoid overwriting the shared array
+		})
+
+		n2 := txn.writeNode(child)
+		n2.prefixes = child.prefixes[commonLen:]
+
+		parent.updateEdge(searches[0], n1)
+
+		n1.addEdge(edge[V]{label: n2.prefixes[0], node: n2})
+
+		leaf := &TypedLeaf[V]{
+			key:   k,
+			value: v,
+		}
+		txn.tree.size++
+
+		prefixes := searches[commonLen:]
+		if len(prefixes) == 0 {
+			n1.leaf = leaf
+		} else {
+			n1.addEdge(edge[V]{
+				label: prefixes[0],
+				node: txn.newNode(&node[V]{
+					leaf:     leaf,
+					prefixes: prefixes,
+				}),
+			})
+		}
+
+		return true
+	}
+}
+
+// Delete key-value pair from the transaction and returns its value and true.
+// If key not found, returns zero value and false.
+func (txn *Txn[V]) Delete(key string) (value V, deleted bool) {
+	// do not copy any node if the key does not exist
+	if _, ok := txn.tree.Get(key); !ok {
+		return value, false
+	}
+
+	// the key exists, so the search never fails
+	searches := []rune(key)
+	var parent *node[V]
+	var parentLabel rune
+	n := txn.writeNode(txn.tree.root)
+	txn.tree.root = n
+	for len(searches) > 0 {
+		child := txn.writeNode(n.getChild(searches[0]))
+		n.updateEdge(searches[0], child)
+
+		parent = n
+		parentLabel = searches[0]
+		n = child
+
+		searches = searches[len(n.prefixes):]
+	}
+
+	leaf := n.leaf
+	n.leaf = nil
+	txn.tree.size--
+
+	// same as Tree.Delete(), all of n and parent are owned by the transaction
+	if parent != nil && len(n.edges) == 0 {
+		parent.deleteEdge(parentLabel)
+	}
+
+	if n != txn.tree.root && len(n.edges) == 1 {
+		txn.mergeChild(n)
+	}
+
+	if parent != nil && parent != txn.tree.root && len(parent.edges) == 1 && !parent.isLeaf() {
+		txn.mergeChild(parent)
+	}
+
+	return leaf.value, true
+}
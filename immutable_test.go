@@ -0,0 +1,295 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestImmutableSnapshot(t *testing.T) {
+	keys := []string{
+		"romane",
+		"romanus",
+		"romulus",
+		"rubens",
+		"ruber",
+		"rubicon",
+		"rubicundus",
+	}
+
+	// keep every version of the tree
+	versions := []*ImmutableTree[int]{NewImmutableTree[int]()}
+	for i, key := range keys {
+		r, inserted := versions[len(versions)-1].Insert(key, i)
+		if !inserted {
+			t.Fatalf("insert failed %v", key)
+		}
+		versions = append(versions, r)
+	}
+
+	// the old versions must not be affected by the later inserts
+	for i, r := range versions {
+		if r.Len() != i {
+			t.Fatalf("expected length=%v, got=%v", i, r.Len())
+		}
+		if reflect.DeepEqual(r.CollectKeys(""), append([]string{}, keys[:i]...)) == false {
+			t.Fatalf("version %v: expected=%v, got=%v", i, keys[:i], r.CollectKeys(""))
+		}
+	}
+
+	// delete from the latest version
+	r := versions[len(versions)-1]
+	for i, key := range keys {
+		var value int
+		var deleted bool
+		r, value, deleted = r.Delete(key)
+		if !deleted || value != i {
+			t.Fatalf("delete failed %v", key)
+		}
+	}
+	if r.Len() != 0 {
+		t.Fatalf("expected length=%v, got=%v", 0, r.Len())
+	}
+
+	// the latest version still has all keys
+	latest := versions[len(versions)-1]
+	for i, key := range keys {
+		value, ok := latest.Get(key)
+		if !ok || value != i {
+			t.Fatalf("key=%v, expected=%v, got=%v", key, i, value)
+		}
+	}
+
+	// delete the key not in the tree returns the same tree
+	if r2, _, deleted := latest.Delete("rom"); deleted || r2 != latest {
+		t.Fatalf("delete unexpected, %v", "rom")
+	}
+}
+
+func TestImmutableIterator(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"}
+
+	old := NewImmutableTree[int]()
+	for i, key := range keys {
+		old, _ = old.Insert(key, i)
+	}
+
+	// pause the iterator in the middle of the old snapshot
+	it := old.Iterator()
+	got := []string{}
+	for ok := it.First(); ok && it.Key() < "rubens"; ok = it.Next() {
+		got = append(got, it.Key())
+	}
+
+	// commit the newer versions, which share the nodes with the old snapshot
+	r, _, _ := old.Delete("rubens")
+	r, _ = r.Insert("rubber", 100)
+	txn := r.Txn()
+	txn.Delete("rubicon")
+	txn.Insert("rubicons", 101)
+	txn.Commit()
+
+	// resume, the iterator still sees the old snapshot
+	for ok := it.Valid(); ok; ok = it.Next() {
+		got = append(got, it.Key())
+	}
+	if reflect.DeepEqual(got, keys) == false {
+		t.Fatalf("expected=%v, got=%v", keys, got)
+	}
+
+	// and backward
+	got = []string{}
+	for ok := it.Last(); ok; ok = it.Prev() {
+		got = append([]string{it.Key()}, got...)
+	}
+	if reflect.DeepEqual(got, keys) == false {
+		t.Fatalf("expected=%v, got=%v", keys, got)
+	}
+}
+
+func TestImmutableTxn(t *testing.T) {
+	base, _ := NewImmutableTree[int]().Insert("shore", 0)
+
+	txn := base.Txn()
+	txn.Insert("sea", 1)
+	txn.Insert("sells", 2)
+	txn.Insert("shells", 3)
+	txn.Insert("shore", 4)
+	txn.Delete("sells")
+
+	if v, ok := txn.Get("shore"); !ok || v != 4 {
+		t.Fatalf("expected=%v, got=%v", 4, v)
+	}
+
+	r1 := txn.Commit()
+
+	// continue the transaction after commit
+	txn.Insert("she", 5)
+	r2 := txn.Commit()
+
+	tests := []struct {
+		tree     *ImmutableTree[int]
+		expected map[string]int
+	}{
+		{base, map[string]int{"shore": 0}},
+		{r1, map[string]int{"sea": 1, "shells": 3, "shore": 4}},
+		{r2, map[string]int{"sea": 1, "she": 5, "shells": 3, "shore": 4}},
+	}
+
+	for _, tt := range tests {
+		if reflect.DeepEqual(tt.tree.ToMap(), tt.expected) == false {
+			t.Fatalf("expected=%v, got=%v", tt.expected, tt.tree.ToMap())
+		}
+	}
+}
+
+func TestImmutableSameAsTree(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	r1 := NewTree[int]()
+	r2 := NewImmutableTree[int]()
+	snapshots := []*ImmutableTree[int]{}
+	maps := []map[string]int{}
+
+	// apply the same random operations to both trees
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("%x", rnd.Intn(512))
+		if rnd.Intn(3) == 0 {
+			_, ok1 := r1.Delete(key)
+			var ok2 bool
+			r2, _, ok2 = r2.Delete(key)
+			if ok1 != ok2 {
+				t.Fatalf("delete %v: expected=%v, got=%v", key, ok1, ok2)
+			}
+		} else {
+			ok1 := r1.Insert(key, i)
+			var ok2 bool
+			r2, ok2 = r2.Insert(key, i)
+			if ok1 != ok2 {
+				t.Fatalf("insert %v: expected=%v, got=%v", key, ok1, ok2)
+			}
+		}
+
+		if i%500 == 0 {
+			snapshots = append(snapshots, r2)
+			maps = append(maps, r1.ToMap())
+		}
+	}
+
+	if reflect.DeepEqual(r1.ToMap(), r2.ToMap()) == false {
+		t.Fatalf("trees differ")
+	}
+
+	// the walks in the other orders
+	keys := func(walk func(TypedWalkCallback[int])) []string {
+		keys := []string{}
+		walk(func(k string, v int) bool {
+			keys = append(keys, k)
+			return false
+		})
+		return keys
+	}
+	if reflect.DeepEqual(keys(r1.WalkReverse), keys(r2.WalkReverse)) == false {
+		t.Fatalf("reverse walks differ")
+	}
+	for _, prefix := range []string{"", "1", "1f", "abc"} {
+		k1 := keys(func(fn TypedWalkCallback[int]) { r1.WalkPrefix(prefix, fn) })
+		k2 := keys(func(fn TypedWalkCallback[int]) { r2.WalkPrefix(prefix, fn) })
+		if reflect.DeepEqual(k1, k2) == false {
+			t.Fatalf("prefix walk %v: expected=%v, got=%v", prefix, k1, k2)
+		}
+		k1 = keys(func(fn TypedWalkCallback[int]) { r1.WalkPath(prefix+"ff", fn) })
+		k2 = keys(func(fn TypedWalkCallback[int]) { r2.WalkPath(prefix+"ff", fn) })
+		if reflect.DeepEqual(k1, k2) == false {
+			t.Fatalf("path walk %v: expected=%v, got=%v", prefix+"ff", k1, k2)
+		}
+	}
+
+	// the range scans
+	for _, rg := range [][2]string{{"", "~"}, {"1", "2"}, {"1f", "a"}, {"b", "a"}} {
+		k1 := keys(func(fn TypedWalkCallback[int]) { r1.Range(rg[0], rg[1], fn) })
+		k2 := keys(func(fn TypedWalkCallback[int]) { r2.Range(rg[0], rg[1], fn) })
+		if reflect.DeepEqual(k1, k2) == false {
+			t.Fatalf("range %v: expected=%v, got=%v", rg, k1, k2)
+		}
+		k1 = keys(func(fn TypedWalkCallback[int]) { r1.RangeReverse(rg[0], rg[1], fn) })
+		k2 = keys(func(fn TypedWalkCallback[int]) { r2.RangeReverse(rg[0], rg[1], fn) })
+		if reflect.DeepEqual(k1, k2) == false {
+			t.Fatalf("reverse range %v: expected=%v, got=%v", rg, k1, k2)
+		}
+	}
+
+	// the lookups through the prefixes
+	for i := 0; i < 512; i++ {
+		key := fmt.Sprintf("%x", i)
+		if reflect.DeepEqual(r1.PrefixMatches(key), r2.PrefixMatches(key)) == false {
+			t.Fatalf("prefix matches %v: expected=%v, got=%v", key, r1.PrefixMatches(key), r2.PrefixMatches(key))
+		}
+		k1, v1, ok1 := r1.ShortestMatch(key)
+		k2, v2, ok2 := r2.ShortestMatch(key)
+		if k1 != k2 || v1 != v2 || ok1 != ok2 {
+			t.Fatalf("shortest match %v: expected=%v %v %v, got=%v %v %v", key, k1, v1, ok1, k2, v2, ok2)
+		}
+	}
+
+	// every snapshot keeps its contents
+	for i, s := range snapshots {
+		if reflect.DeepEqual(s.ToMap(), maps[i]) == false {
+			t.Fatalf("snapshot %v was modified", i)
+		}
+	}
+}
+
+//
+// go test -race -run Immutable
+//
+
+func TestImmutableConcurrent(t *testing.T) {
+	var current atomic.Value
+	current.Store(NewImmutableTree[int]())
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	// readers never lock, each snapshot must be consistent
+	for rd := 0; rd < 4; rd++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				r := current.Load().(*ImmutableTree[int])
+				n := 0
+				r.Walk(func(k string, v int) bool {
+					n++
+					return false
+				})
+				if n != r.Len() {
+					t.Errorf("expected length=%v, got=%v", r.Len(), n)
+					return
+				}
+			}
+		}()
+	}
+
+	// single writer publishes new versions
+	for i := 0; i < 1000; i++ {
+		r := current.Load().(*ImmutableTree[int])
+		txn := r.Txn()
+		txn.Insert(fmt.Sprintf("key%d", i), i)
+		if i%3 == 0 {
+			txn.Delete(fmt.Sprintf("key%d", i/2))
+		}
+		current.Store(txn.Commit())
+	}
+
+	close(done)
+	wg.Wait()
+}
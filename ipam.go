@@ -0,0 +1,138 @@
+package radix
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+//
+// Pool is the IP address allocator which hands out the subnets of the parent prefix.
+// The allocated subnets are recorded in the PrefixTable,
+// and the free blocks are found by splitting the parent prefix into halves
+// until each half is entirely free or entirely allocated.
+//
+//   pool, _ := NewPool(netip.MustParsePrefix("10.0.0.0/16"))
+//   p1, _ := pool.Allocate(24) // 10.0.0.0/24
+//   p2, _ := pool.Allocate(26) // 10.0.1.0/26
+//   pool.Release(p1)
+//
+
+var (
+	ErrPoolExhausted = errors.New("radix: no free block in the pool")
+	ErrOutOfPool     = errors.New("radix: prefix is out of the pool")
+	ErrOverlap       = errors.New("radix: prefix overlaps with allocated one")
+	ErrNotAllocated  = errors.New("radix: prefix is not allocated")
+)
+
+// Pool definition
+type Pool struct {
+	prefix    netip.Prefix
+	allocated *PrefixTable[struct{}]
+}
+
+// Constructor
+// NewPool() returns empty Pool which allocates the subnets of the given prefix
+func NewPool(prefix netip.Prefix) (*Pool, error) {
+	if !prefix.IsValid() {
+		return nil, fmt.Errorf("radix: invalid pool prefix %v", prefix)
+	}
+	return &Pool{
+		prefix:    prefix.Masked(),
+		allocated: NewPrefixTable[struct{}](),
+	}, nil
+}
+
+// Prefix() returns the parent prefix of the pool
+func (p *Pool) Prefix() netip.Prefix {
+	return p.prefix
+}
+
+// returns the allocated subnets as a PrefixSet, they never overlap
+func (p *Pool) used() *PrefixSet {
+	return &PrefixSet{table: p.allocated}
+}
+
+// returns true if the prefix is inside the pool
+func (p *Pool) contains(prefix netip.Prefix) bool {
+	return prefix.IsValid() &&
+		prefix.Addr().Is4() == p.prefix.Addr().Is4() &&
+		prefix.Bits() >= p.prefix.Bits() &&
+		p.prefix.Contains(prefix.Addr())
+}
+
+// Allocate() finds the first free subnet of the given prefix length, allocates and returns it
+func (p *Pool) Allocate(bits int) (netip.Prefix, error) {
+	if bits < p.prefix.Bits() || bits > p.prefix.Addr().BitLen() {
+		return netip.Prefix{}, fmt.Errorf("radix: invalid prefix length %d for pool %v", bits, p.prefix)
+	}
+
+	prefix, ok := p.findFree(p.prefix, bits)
+	if !ok {
+		return netip.Prefix{}, ErrPoolExhausted
+	}
+	p.allocated.Insert(prefix, struct{}{})
+	return prefix, nil
+}
+
+// findFree() returns the first free subnet of the given length inside the block
+func (p *Pool) findFree(block netip.Prefix, bits int) (netip.Prefix, bool) {
+	used := p.used()
+	if used.covers(block) {
+		return netip.Prefix{}, false
+	}
+	if !used.overlaps(block) {
+		return netip.PrefixFrom(block.Addr(), bits), true
+	}
+	if block.Bits() >= bits {
+		return netip.Prefix{}, false
+	}
+
+	lower, upper := splitPrefix(block)
+	if prefix, ok := p.findFree(lower, bits); ok {
+		return prefix, true
+	}
+	return p.findFree(upper, bits)
+}
+
+// AllocateSpecific() allocates the given subnet if it is free
+func (p *Pool) AllocateSpecific(prefix netip.Prefix) error {
+	if !p.contains(prefix) {
+		return ErrOutOfPool
+	}
+	prefix = prefix.Masked()
+
+	used := p.used()
+	if used.covers(prefix) || used.overlaps(prefix) {
+		return ErrOverlap
+	}
+	p.allocated.Insert(prefix, struct{}{})
+	return nil
+}
+
+// Release() returns the allocated subnet to the pool
+func (p *Pool) Release(prefix netip.Prefix) error {
+	if _, ok := p.allocated.Delete(prefix.Masked()); !ok {
+		return ErrNotAllocated
+	}
+	return nil
+}
+
+// Allocated() returns the allocated subnets sorted by address
+func (p *Pool) Allocated() []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	p.allocated.Walk(func(prefix netip.Prefix, v struct{}) bool {
+		prefixes = append(prefixes, prefix)
+		return false
+	})
+	return prefixes
+}
+
+// FreeBlocks() returns the unallocated ranges as the largest aligned prefixes, sorted by address
+func (p *Pool) FreeBlocks() []netip.Prefix {
+	free := p.used().subtract(p.prefix)
+	if free == nil {
+		return []netip.Prefix{}
+	}
+	return free
+}
@@ -0,0 +1,97 @@
+package radix
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	pool, err := NewPool(netip.MustParsePrefix("10.0.0.0/16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocations := []struct {
+		bits     int
+		expected string
+	}{
+		{24, "10.0.0.0/24"},
+		{26, "10.0.1.0/26"},
+		{24, "10.0.2.0/24"},
+		{25, "10.0.1.128/25"},
+		{26, "10.0.1.64/26"},
+		{17, "10.0.128.0/17"},
+	}
+
+	for _, a := range allocations {
+		p, err := pool.Allocate(a.bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.String() != a.expected {
+			t.Fatalf("expected=%v, got=%v", a.expected, p)
+		}
+	}
+
+	expected := parsePrefixes("10.0.3.0/24", "10.0.4.0/22", "10.0.8.0/21", "10.0.16.0/20", "10.0.32.0/19", "10.0.64.0/18")
+	if got := pool.FreeBlocks(); reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	// release and reuse
+	if err := pool.Release(netip.MustParsePrefix("10.0.1.64/26")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Release(netip.MustParsePrefix("10.0.1.64/26")); !errors.Is(err, ErrNotAllocated) {
+		t.Fatalf("expected=%v, got=%v", ErrNotAllocated, err)
+	}
+	if p, _ := pool.Allocate(27); p.String() != "10.0.1.64/27" {
+		t.Fatalf("expected=%v, got=%v", "10.0.1.64/27", p)
+	}
+
+	// specific allocation
+	tests := []struct {
+		prefix   string
+		expected error
+	}{
+		{"10.0.3.0/25", nil},
+		{"10.0.3.0/26", ErrOverlap},
+		{"10.0.0.0/23", ErrOverlap},
+		{"10.1.0.0/24", ErrOutOfPool},
+		{"10.0.0.0/8", ErrOutOfPool},
+		{"2001:db8::/64", ErrOutOfPool},
+		{"10.0.3.128/25", nil},
+	}
+	for _, tt := range tests {
+		err := pool.AllocateSpecific(netip.MustParsePrefix(tt.prefix))
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("prefix=%v, expected=%v, got=%v", tt.prefix, tt.expected, err)
+		}
+	}
+
+	// invalid length
+	if _, err := pool.Allocate(8); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// allocate all
+	for {
+		if _, err := pool.Allocate(18); err != nil {
+			if !errors.Is(err, ErrPoolExhausted) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	expected = parsePrefixes("10.0.1.96/27", "10.0.4.0/22", "10.0.8.0/21", "10.0.16.0/20", "10.0.32.0/19")
+	if got := pool.FreeBlocks(); reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	expected = parsePrefixes("10.0.0.0/24", "10.0.1.0/26", "10.0.1.64/27", "10.0.1.128/25", "10.0.2.0/24", "10.0.3.0/25", "10.0.3.128/25", "10.0.64.0/18", "10.0.128.0/17")
+	if got := pool.Allocated(); reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+}
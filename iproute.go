@@ -0,0 +1,496 @@
+package radix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//
+// Reader and writer of the Linux routing tables in the format of `ip route` and `ip -6 route`.
+//
+//   default via 192.168.0.1 dev eth0 proto dhcp src 192.168.0.10 metric 100
+//   192.168.0.0/24 dev eth0 proto kernel scope link src 192.168.0.10 metric 100
+//   local 192.168.0.10 dev eth0 table local proto kernel scope host src 192.168.0.10
+//   default proto static metric 100
+//   	nexthop via 10.0.0.1 dev eth1 weight 1
+//   	nexthop via 10.0.0.2 dev eth2 weight 1
+//
+// The routes are loaded into MultiTable keyed by the table number,
+// so the longest match can be run against the captured router state.
+//
+//   m, _ := LoadIPRoutes(f, FamilyIPv4)
+//   _, prefix, routes, ok := m.Lookup(TableMain, netip.MustParseAddr("10.0.0.1"))
+//
+// "default" does not tell the address family, `ip -4 route` and `ip -6 route` print it in the same way.
+// The family of the dump should be given, as the options -4 and -6 of `ip`.
+// If FamilyUnspec is given, the family of "default" is decided by the gateway, the source address or "pref",
+// and the route without them, such as "default dev tun0 metric 1024", is the error.
+// WriteIPRoutes() writes such route as "0.0.0.0/0" or "::/0" instead of "default", so it can be read back.
+//
+// The keywords which are not known are kept in Extra as they are, for example "pref medium" or "linkdown",
+// and written back after the known keywords.
+//
+
+// Linux routing table numbers
+const (
+	TableUnspec  TableID = 0
+	TableDefault TableID = 253
+	TableMain    TableID = 254
+	TableLocal   TableID = 255
+)
+
+// IPFamily definition, the address family of the routes
+type IPFamily int
+
+// address families, same as the options -4 and -6 of `ip`
+const (
+	FamilyUnspec IPFamily = 0 // both IPv4 and IPv6 routes
+	FamilyIPv4   IPFamily = 4
+	FamilyIPv6   IPFamily = 6
+)
+
+// names of the tables in /etc/iproute2/rt_tables
+var ipRouteTables = map[string]TableID{
+	"unspec":  TableUnspec,
+	"default": TableDefault,
+	"main":    TableMain,
+	"local":   TableLocal,
+}
+
+// route types printed before the destination, unicast is omitted
+var ipRouteTypes = map[string]bool{
+	"unicast":     true,
+	"local":       true,
+	"broadcast":   true,
+	"multicast":   true,
+	"anycast":     true,
+	"unreachable": true,
+	"blackhole":   true,
+	"prohibit":    true,
+	"throw":       true,
+	"nat":         true,
+}
+
+// keywords which take a value
+var ipRouteKeys = map[string]bool{
+	"via":    true,
+	"dev":    true,
+	"table":  true,
+	"proto":  true,
+	"scope":  true,
+	"src":    true,
+	"metric": true,
+}
+
+var ipNextHopKeys = map[string]bool{
+	"via":    true,
+	"dev":    true,
+	"weight": true,
+}
+
+// IPRoute definition, a line of `ip route`
+type IPRoute struct {
+	Type     string       // "unicast", "local", "broadcast", "unreachable" and so on
+	Dst      netip.Prefix // destination, "default" is 0.0.0.0/0 or ::/0
+	Via      netip.Addr   // gateway, invalid if the route has no gateway
+	Dev      string
+	Table    TableID
+	Proto    string
+	Scope    string
+	Src      netip.Addr // preferred source address
+	Metric   uint32
+	NextHops []IPNextHop // multipath routes
+	Extra    []string    // unknown keywords and their values
+}
+
+// IPNextHop definition, a nexthop line of the multipath route
+type IPNextHop struct {
+	Via    netip.Addr
+	Dev    string
+	Weight int
+	Extra  []string
+}
+
+// returns the table number of the name, or the numeric table
+func parseTableID(s string) (TableID, error) {
+	if id, ok := ipRouteTables[s]; ok {
+		return id, nil
+	}
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("radix: unknown table %q", s)
+	}
+	return TableID(id), nil
+}
+
+// returns the name of the table, or the number
+func formatTableID(id TableID) string {
+	for name, n := range ipRouteTables {
+		if n == id {
+			return name
+		}
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// ParseIPRoute() parses a line of `ip route` or `ip -6 route`, family is the address family of the route.
+// The nexthops of the multipath route may follow on the same line, as `ip -o route` prints.
+func ParseIPRoute(line string, family IPFamily) (IPRoute, error) {
+	return parseIPRoute(strings.Fields(line), family)
+}
+
+func parseIPRoute(fields []string, family IPFamily) (IPRoute, error) {
+	route := IPRoute{Type: "unicast", Table: TableMain}
+	if family != FamilyUnspec && family != FamilyIPv4 && family != FamilyIPv6 {
+		return route, fmt.Errorf("radix: unknown address family %d", family)
+	}
+	if len(fields) > 0 && ipRouteTypes[fields[0]] {
+		route.Type = fields[0]
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return route, fmt.Errorf("radix: missing destination")
+	}
+	dst := fields[0]
+
+	// address families suggested by the keywords, used to decide the family of "default"
+	var families []bool
+	for i := 1; i < len(fields); i++ {
+		key := fields[i]
+		if key == `\` {
+			continue
+		}
+		if key == "nexthop" {
+			route.NextHops = append(route.NextHops, IPNextHop{})
+			continue
+		}
+
+		keys := ipRouteKeys
+		if len(route.NextHops) > 0 {
+			keys = ipNextHopKeys
+		}
+		if !keys[key] {
+			if len(route.NextHops) > 0 {
+				nh := &route.NextHops[len(route.NextHops)-1]
+				nh.Extra = append(nh.Extra, key)
+			} else {
+				route.Extra = append(route.Extra, key)
+			}
+			continue
+		}
+
+		// "via inet6 fe80::1" is the gateway of the other address family
+		explicit := false
+		if key == "via" && i+1 < len(fields) && (fields[i+1] == "inet" || fields[i+1] == "inet6") {
+			explicit = true
+			i++
+		}
+		if i+1 >= len(fields) {
+			return route, fmt.Errorf("radix: missing value of %q", key)
+		}
+		i++
+		value := fields[i]
+
+		var err error
+		if len(route.NextHops) > 0 {
+			err = route.NextHops[len(route.NextHops)-1].set(key, value)
+		} else {
+			err = route.set(key, value)
+		}
+		if err != nil {
+			return route, err
+		}
+		if key == "via" {
+			// the gateway of the other family is only IPv6 gateway of IPv4 route (RFC 5549) in Linux
+			addr, _ := netip.ParseAddr(value)
+			families = append(families, addr.Is4() != explicit)
+		}
+	}
+
+	if route.Src.IsValid() {
+		families = append([]bool{route.Src.Is4()}, families...)
+	}
+	if slices.Contains(route.Extra, "pref") {
+		// route preference is only for IPv6
+		families = append([]bool{false}, families...)
+	}
+
+	var err error
+	if route.Dst, err = parseIPRouteDst(dst, family, families); err != nil {
+		return route, err
+	}
+	return route, nil
+}
+
+// returns the prefix of the destination, the address without length is the host route.
+// The family of "default" is the given family, or the first of the suggested families if FamilyUnspec.
+func parseIPRouteDst(dst string, family IPFamily, families []bool) (netip.Prefix, error) {
+	if dst == "default" {
+		if family == FamilyUnspec {
+			if len(families) == 0 {
+				return netip.Prefix{}, fmt.Errorf("radix: address family of %q is ambiguous, specify the family", dst)
+			}
+			family = FamilyIPv6
+			if families[0] {
+				family = FamilyIPv4
+			}
+		}
+		if family == FamilyIPv4 {
+			return netip.PrefixFrom(netip.IPv4Unspecified(), 0), nil
+		}
+		return netip.PrefixFrom(netip.IPv6Unspecified(), 0), nil
+	}
+
+	p, err := parseIPRoutePrefix(dst)
+	if err != nil {
+		return p, err
+	}
+	if (family == FamilyIPv4 && !p.Addr().Is4()) || (family == FamilyIPv6 && p.Addr().Is4()) {
+		return p, fmt.Errorf("radix: destination %q is not in the family IPv%d", dst, family)
+	}
+	return p, nil
+}
+
+// returns the prefix, or the host route if the address has no length
+func parseIPRoutePrefix(dst string) (netip.Prefix, error) {
+	if strings.Contains(dst, "/") {
+		p, err := netip.ParsePrefix(dst)
+		if err != nil {
+			return p, fmt.Errorf("radix: invalid destination %q", dst)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(dst)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("radix: invalid destination %q", dst)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// set the value of the keyword
+func (r *IPRoute) set(key, value string) error {
+	var err error
+	switch key {
+	case "via":
+		r.Via, err = netip.ParseAddr(value)
+	case "dev":
+		r.Dev = value
+	case "table":
+		r.Table, err = parseTableID(value)
+	case "proto":
+		r.Proto = value
+	case "scope":
+		r.Scope = value
+	case "src":
+		r.Src, err = netip.ParseAddr(value)
+	case "metric":
+		var metric uint64
+		metric, err = strconv.ParseUint(value, 10, 32)
+		r.Metric = uint32(metric)
+	}
+	if err != nil {
+		return fmt.Errorf("radix: invalid %s %q", key, value)
+	}
+	return nil
+}
+
+// set the value of the keyword
+func (nh *IPNextHop) set(key, value string) error {
+	var err error
+	switch key {
+	case "via":
+		nh.Via, err = netip.ParseAddr(value)
+	case "dev":
+		nh.Dev = value
+	case "weight":
+		nh.Weight, err = strconv.Atoi(value)
+	}
+	if err != nil {
+		return fmt.Errorf("radix: invalid %s %q", key, value)
+	}
+	return nil
+}
+
+// returns the gateway with the address family if it differs from the destination
+func formatVia(via netip.Addr, is4 bool) []string {
+	switch {
+	case via.Is4() == is4:
+		return []string{"via", via.String()}
+	case via.Is4():
+		return []string{"via", "inet", via.String()}
+	default:
+		return []string{"via", "inet6", via.String()}
+	}
+}
+
+// returns true if the family of "default" can be decided by the keywords of the route
+func (r IPRoute) hasFamilyHint() bool {
+	if r.Via.IsValid() || r.Src.IsValid() || slices.Contains(r.Extra, "pref") {
+		return true
+	}
+	for _, nh := range r.NextHops {
+		if nh.Via.IsValid() {
+			return true
+		}
+	}
+	return false
+}
+
+// String() returns the route in the format of `ip route`.
+// The default route is written as "0.0.0.0/0" or "::/0" if its family can not be decided by the keywords.
+// The nexthops of the multipath route are printed on the following lines starting with a tab.
+func (r IPRoute) String() string {
+	is4 := r.Dst.Addr().Is4()
+	fields := []string{}
+	if r.Type != "" && r.Type != "unicast" {
+		fields = append(fields, r.Type)
+	}
+
+	switch {
+	case r.Dst.Bits() == 0 && r.hasFamilyHint():
+		fields = append(fields, "default")
+	case r.Dst.IsSingleIP():
+		fields = append(fields, r.Dst.Addr().String())
+	default:
+		fields = append(fields, r.Dst.String())
+	}
+
+	if r.Via.IsValid() {
+		fields = append(fields, formatVia(r.Via, is4)...)
+	}
+	if r.Dev != "" {
+		fields = append(fields, "dev", r.Dev)
+	}
+	if r.Table != TableMain {
+		fields = append(fields, "table", formatTableID(r.Table))
+	}
+	if r.Proto != "" {
+		fields = append(fields, "proto", r.Proto)
+	}
+	if r.Scope != "" {
+		fields = append(fields, "scope", r.Scope)
+	}
+	if r.Src.IsValid() {
+		fields = append(fields, "src", r.Src.String())
+	}
+	// IPv6 routes always have the metric
+	if r.Metric != 0 || !is4 {
+		fields = append(fields, "metric", strconv.FormatUint(uint64(r.Metric), 10))
+	}
+	fields = append(fields, r.Extra...)
+
+	var b strings.Builder
+	b.WriteString(strings.Join(fields, " "))
+	for _, nh := range r.NextHops {
+		fields := []string{"nexthop"}
+		if nh.Via.IsValid() {
+			fields = append(fields, formatVia(nh.Via, is4)...)
+		}
+		if nh.Dev != "" {
+			fields = append(fields, "dev", nh.Dev)
+		}
+		if nh.Weight != 0 {
+			fields = append(fields, "weight", strconv.Itoa(nh.Weight))
+		}
+		fields = append(fields, nh.Extra...)
+		b.WriteString("\n\t")
+		b.WriteString(strings.Join(fields, " "))
+	}
+	return b.String()
+}
+
+// ReadIPRoutes() reads the output of `ip -4 route show table all` or `ip -6 route show table all`,
+// family is FamilyIPv4 or FamilyIPv6 respectively, or FamilyUnspec if both are in the input.
+// The nexthop lines of the multipath route are joined to the preceding route.
+// Empty lines and the lines starting with '#' are skipped.
+func ReadIPRoutes(r io.Reader, family IPFamily) ([]IPRoute, error) {
+	routes := []IPRoute{}
+	var fields []string
+	start := 0
+
+	flush := func() error {
+		if fields == nil {
+			return nil
+		}
+		route, err := parseIPRoute(fields, family)
+		if err != nil {
+			return fmt.Errorf("radix: line %d: %w", start, err)
+		}
+		routes = append(routes, route)
+		fields = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// continuation of the multipath route
+		if fields != nil && (line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(trimmed, "nexthop")) {
+			fields = append(fields, strings.Fields(trimmed)...)
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return routes, err
+		}
+		fields = strings.Fields(trimmed)
+		start = lineNum
+	}
+	if err := scanner.Err(); err != nil {
+		return routes, err
+	}
+	return routes, flush()
+}
+
+// LoadIPRoutes() reads the routes by ReadIPRoutes() and returns the MultiTable keyed by the table number.
+// The routes to the same destination in the same table, for example with different metrics, are stored together.
+func LoadIPRoutes(r io.Reader, family IPFamily) (*MultiTable[[]IPRoute], error) {
+	routes, err := ReadIPRoutes(r, family)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMultiTable[[]IPRoute]()
+	for _, route := range routes {
+		existing, _ := m.Get(route.Table, route.Dst)
+		m.Insert(route.Table, route.Dst, append(existing, route))
+	}
+	return m, nil
+}
+
+// WriteIPRoutes() writes all routes in the MultiTable in the format of `ip route`.
+// IPv4 routes of all tables come first, then IPv6 routes, the tables are in ascending order.
+func WriteIPRoutes(w io.Writer, m *MultiTable[[]IPRoute]) error {
+	var err error
+	for _, is4 := range []bool{true, false} {
+		for _, id := range m.Tables() {
+			m.Walk(id, func(p netip.Prefix, routes []IPRoute) bool {
+				if p.Addr().Is4() != is4 {
+					return false
+				}
+				for _, route := range routes {
+					if _, err = fmt.Fprintln(w, route.String()); err != nil {
+						return true
+					}
+				}
+				return false
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
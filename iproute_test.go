@@ -0,0 +1,210 @@
+package radix
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// output of `ip route show table all` and `ip -6 route show table all`,
+// sorted by address family, table and prefix as WriteIPRoutes() writes
+const ipRouteDump = `10.0.0.0/8 via 10.255.0.1 dev eth1 table 100 proto static
+default via inet6 fe80::1 dev eth0 table 200 proto static
+default via 192.168.0.1 dev eth0 proto dhcp src 192.168.0.10 metric 100
+10.1.0.0/16 proto static metric 20
+	nexthop via 10.255.0.1 dev eth1 weight 1
+	nexthop via 10.255.0.2 dev eth2 weight 2
+blackhole 10.2.0.0/16 proto static
+192.168.0.0/24 dev eth0 proto kernel scope link src 192.168.0.10 metric 100
+192.168.0.0/24 dev eth3 proto kernel scope link src 192.168.0.11 metric 200 linkdown
+local 127.0.0.0/8 dev lo table local proto kernel scope host src 127.0.0.1
+local 127.0.0.1 dev lo table local proto kernel scope host src 127.0.0.1
+broadcast 192.168.0.255 dev eth0 table local proto kernel scope link src 192.168.0.10
+default via fe80::1 dev eth0 proto ra metric 1024 expires 1798sec hoplimit 64 pref medium
+2001:db8::/64 dev eth0 proto kernel metric 256 pref medium
+fe80::/64 dev eth0 proto kernel metric 256 pref medium
+anycast fe80:: dev eth0 table local proto kernel metric 0 pref medium
+local fe80::1 dev eth0 table local proto kernel metric 0 pref medium
+multicast ff00::/8 dev eth0 table local proto kernel metric 256 pref medium
+unreachable default dev lo table 300 metric 4294967295 pref medium
+::/0 dev tun0 table 400 metric 1024
+`
+
+func TestParseIPRoute(t *testing.T) {
+	p := netip.MustParsePrefix
+	a := netip.MustParseAddr
+
+	tests := []struct {
+		line     string
+		expected IPRoute
+	}{
+		{
+			"default via 192.168.0.1 dev eth0 proto dhcp src 192.168.0.10 metric 100",
+			IPRoute{Type: "unicast", Dst: p("0.0.0.0/0"), Via: a("192.168.0.1"), Dev: "eth0", Table: TableMain, Proto: "dhcp", Src: a("192.168.0.10"), Metric: 100},
+		},
+		{
+			"local 127.0.0.1 dev lo table local proto kernel scope host src 127.0.0.1",
+			IPRoute{Type: "local", Dst: p("127.0.0.1/32"), Dev: "lo", Table: TableLocal, Proto: "kernel", Scope: "host", Src: a("127.0.0.1")},
+		},
+		{
+			"default dev eth0 metric 1024 pref medium",
+			IPRoute{Type: "unicast", Dst: p("::/0"), Dev: "eth0", Table: TableMain, Metric: 1024, Extra: []string{"pref", "medium"}},
+		},
+		{
+			"default via inet6 fe80::1 dev eth0",
+			IPRoute{Type: "unicast", Dst: p("0.0.0.0/0"), Via: a("fe80::1"), Dev: "eth0", Table: TableMain},
+		},
+		{
+			`10.1.0.0/16 table 100 proto static \	nexthop via 10.255.0.1 dev eth1 weight 1 \	nexthop via 10.255.0.2 dev eth2 weight 2 dead`,
+			IPRoute{Type: "unicast", Dst: p("10.1.0.0/16"), Table: 100, Proto: "static", NextHops: []IPNextHop{
+				{Via: a("10.255.0.1"), Dev: "eth1", Weight: 1},
+				{Via: a("10.255.0.2"), Dev: "eth2", Weight: 2, Extra: []string{"dead"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseIPRoute(tt.line, FamilyUnspec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("expected=%+v, got=%+v", tt.expected, got)
+		}
+	}
+
+	invalids := []string{
+		"",
+		"local",
+		"10.0.0.0/33 dev eth0",
+		"default via",
+		"default via 192.168.0.x",
+		"10.0.0.0/8 table nosuchtable",
+		"10.0.0.0/8 metric -1",
+	}
+	for _, line := range invalids {
+		if _, err := ParseIPRoute(line, FamilyUnspec); err == nil {
+			t.Fatalf("expected error, line=%q", line)
+		}
+	}
+}
+
+func TestParseIPRouteFamily(t *testing.T) {
+	p := netip.MustParsePrefix
+
+	// the tunnel default route of `ip -6 route`, nothing tells the family
+	line := "default dev tun0 metric 1024"
+	if route, err := ParseIPRoute(line, FamilyUnspec); err == nil {
+		t.Fatalf("expected error, got=%v", route.Dst)
+	}
+
+	tests := []struct {
+		line     string
+		family   IPFamily
+		expected netip.Prefix
+	}{
+		{line, FamilyIPv4, p("0.0.0.0/0")},
+		{line, FamilyIPv6, p("::/0")},
+		{"default via inet 192.168.0.1 dev eth0", FamilyUnspec, p("::/0")},
+		{"default via 192.168.0.1 dev eth0", FamilyIPv4, p("0.0.0.0/0")},
+		{"2001:db8::/32 dev eth0", FamilyIPv6, p("2001:db8::/32")},
+	}
+	for _, tt := range tests {
+		route, err := ParseIPRoute(tt.line, tt.family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if route.Dst != tt.expected {
+			t.Fatalf("line=%q, family=%v, expected=%v, got=%v", tt.line, tt.family, tt.expected, route.Dst)
+		}
+	}
+
+	// the destination of the other family
+	invalids := []struct {
+		line   string
+		family IPFamily
+	}{
+		{"10.0.0.0/8 dev eth0", FamilyIPv6},
+		{"2001:db8::/32 dev eth0", FamilyIPv4},
+		{"fe80::1 dev eth0", FamilyIPv4},
+		{"default dev eth0", 5},
+	}
+	for _, tt := range invalids {
+		if _, err := ParseIPRoute(tt.line, tt.family); err == nil {
+			t.Fatalf("expected error, line=%q, family=%v", tt.line, tt.family)
+		}
+	}
+
+	// the IPv6 dump is not loaded into IPv4 table
+	m, err := LoadIPRoutes(strings.NewReader(line+"\n"), FamilyIPv6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, ok := m.Lookup(TableMain, netip.MustParseAddr("8.8.8.8")); ok {
+		t.Fatalf("unexpected IPv4 default route")
+	}
+	if _, prefix, routes, ok := m.Lookup(TableMain, netip.MustParseAddr("2001:db8::1")); !ok || prefix != p("::/0") || routes[0].String() != "::/0 dev tun0 metric 1024" {
+		t.Fatalf("unexpected lookup result %v %v", prefix, routes)
+	}
+}
+
+func TestLoadIPRoutes(t *testing.T) {
+	m, err := LoadIPRoutes(strings.NewReader(ipRouteDump), FamilyUnspec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTables := []TableID{100, 200, TableMain, TableLocal, 300, 400}
+	if got := m.Tables(); !reflect.DeepEqual(got, expectedTables) {
+		t.Fatalf("expected=%v, got=%v", expectedTables, got)
+	}
+
+	tests := []struct {
+		table    TableID
+		addr     string
+		expected string
+	}{
+		{TableMain, "192.168.0.20", "192.168.0.0/24 dev eth0 proto kernel scope link src 192.168.0.10 metric 100"},
+		{TableMain, "10.1.2.3", "10.1.0.0/16 proto static metric 20\n\tnexthop via 10.255.0.1 dev eth1 weight 1\n\tnexthop via 10.255.0.2 dev eth2 weight 2"},
+		{TableMain, "10.2.2.3", "blackhole 10.2.0.0/16 proto static"},
+		{TableMain, "10.3.2.3", "default via 192.168.0.1 dev eth0 proto dhcp src 192.168.0.10 metric 100"},
+		{TableMain, "2001:db8::1", "2001:db8::/64 dev eth0 proto kernel metric 256 pref medium"},
+		{TableMain, "2001:db9::1", "default via fe80::1 dev eth0 proto ra metric 1024 expires 1798sec hoplimit 64 pref medium"},
+		{TableLocal, "127.0.0.1", "local 127.0.0.1 dev lo table local proto kernel scope host src 127.0.0.1"},
+		{100, "10.1.2.3", "10.0.0.0/8 via 10.255.0.1 dev eth1 table 100 proto static"},
+		{200, "8.8.8.8", "default via inet6 fe80::1 dev eth0 table 200 proto static"},
+		{400, "2001:db8::1", "::/0 dev tun0 table 400 metric 1024"},
+	}
+	for _, tt := range tests {
+		_, _, routes, ok := m.Lookup(tt.table, netip.MustParseAddr(tt.addr))
+		if !ok {
+			t.Fatalf("not found, table=%v, addr=%v", tt.table, tt.addr)
+		}
+		if got := routes[0].String(); got != tt.expected {
+			t.Fatalf("expected=%v, got=%v", tt.expected, got)
+		}
+	}
+
+	// routes to the same destination are kept together
+	routes, _ := m.Get(TableMain, netip.MustParsePrefix("192.168.0.0/24"))
+	if len(routes) != 2 || routes[1].Dev != "eth3" {
+		t.Fatalf("unexpected routes %v", routes)
+	}
+
+	var b strings.Builder
+	if err := WriteIPRoutes(&b, m); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != ipRouteDump {
+		t.Fatalf("expected=\n%v\ngot=\n%v", ipRouteDump, b.String())
+	}
+}
+
+func TestReadIPRoutesError(t *testing.T) {
+	dump := "default via 192.168.0.1 dev eth0\n\n10.0.0.0/8 via 10.0.0.x dev eth1\n"
+	_, err := ReadIPRoutes(strings.NewReader(dump), FamilyIPv4)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("expected error at line 3, got=%v", err)
+	}
+}
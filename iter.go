@@ -0,0 +1,71 @@
+package radix
+
+import (
+	"iter"
+)
+
+//
+// Range-over-func iterators.
+// Unlike WalkCallback, the loop can be stopped by break as usual.
+//
+//   for k, v := range t.All() {
+//       if k == "stop" {
+//           break
+//       }
+//   }
+//
+
+// All() returns an iterator over all key-value pairs in ascending order
+func (t *TypedTree[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		walk(t.root, func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Backward() returns an iterator over all key-value pairs in descending order
+func (t *TypedTree[V]) Backward() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.WalkReverse(func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// WithPrefix() returns an iterator over the key-value pairs starting with a given prefix,
+// same as Collect() but without making a slice
+func (t *TypedTree[V]) WithPrefix(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.WalkPrefix(prefix, func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Between() returns an iterator over the key-value pairs where lo <= key < hi, same as Range()
+func (t *TypedTree[V]) Between(lo, hi string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.Range(lo, hi, func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Keys() returns an iterator over all keys in ascending order
+func (t *TypedTree[V]) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		walk(t.root, func(k string, v V) bool {
+			return !yield(k)
+		})
+	}
+}
+
+// Values() returns an iterator over all values in ascending order of the keys
+func (t *TypedTree[V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		walk(t.root, func(k string, v V) bool {
+			return !yield(v)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package radix
+
+import (
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestIterSeq(t *testing.T) {
+	keys := []string{
+		"sea",
+		"sells",
+		"shells",
+		"she",
+		"shore",
+	}
+
+	r := NewTree[int]()
+	for i, key := range keys {
+		r.Insert(key, i)
+	}
+
+	sorted := []string{"sea", "sells", "she", "shells", "shore"}
+
+	got := []string{}
+	for k, v := range r.All() {
+		if value, _ := r.Get(k); value != v {
+			t.Fatalf("key=%v, expected=%v, got=%v", k, value, v)
+		}
+		got = append(got, k)
+	}
+	if reflect.DeepEqual(got, sorted) == false {
+		t.Fatalf("All(): expected=%v, got=%v", sorted, got)
+	}
+
+	got = []string{}
+	for k := range r.Backward() {
+		got = append(got, k)
+	}
+	reversed := slices.Clone(sorted)
+	slices.Reverse(reversed)
+	if reflect.DeepEqual(got, reversed) == false {
+		t.Fatalf("Backward(): expected=%v, got=%v", reversed, got)
+	}
+
+	if got := slices.Collect(r.Keys()); reflect.DeepEqual(got, sorted) == false {
+		t.Fatalf("Keys(): expected=%v, got=%v", sorted, got)
+	}
+
+	if got := slices.Collect(r.Values()); reflect.DeepEqual(got, []int{0, 1, 3, 2, 4}) == false {
+		t.Fatalf("Values(): expected=%v, got=%v", []int{0, 1, 3, 2, 4}, got)
+	}
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", sorted},
+		{"sh", []string{"she", "shells", "shore"}},
+		{"she", []string{"she", "shells"}},
+		{"shop", []string{}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for k := range r.WithPrefix(tt.prefix) {
+			got = append(got, k)
+		}
+		if reflect.DeepEqual(got, tt.expected) == false {
+			t.Fatalf("WithPrefix(%q): expected=%v, got=%v", tt.prefix, tt.expected, got)
+		}
+	}
+
+	got = []string{}
+	for k := range r.Between("sells", "shore") {
+		got = append(got, k)
+	}
+	if reflect.DeepEqual(got, []string{"sells", "she", "shells"}) == false {
+		t.Fatalf("Between(): expected=%v, got=%v", []string{"sells", "she", "shells"}, got)
+	}
+
+	// break stops the walk
+	got = []string{}
+	for k := range r.All() {
+		if k == "she" {
+			break
+		}
+		got = append(got, k)
+	}
+	if reflect.DeepEqual(got, []string{"sea", "sells"}) == false {
+		t.Fatalf("expected=%v, got=%v", []string{"sea", "sells"}, got)
+	}
+}
+
+func TestIterSeqWrappers(t *testing.T) {
+	m := map[string]int{"sea": 0, "sells": 1, "shells": 2, "she": 3, "shore": 4}
+
+	r := NewTree[int]()
+	r.Load(m)
+	s := NewSyncTree[int]()
+	s.Load(m)
+	im := NewImmutableTree[int]()
+	for k, v := range m {
+		im, _ = im.Insert(k, v)
+	}
+
+	type seqs struct {
+		name       string
+		all        iter.Seq2[string, int]
+		backward   iter.Seq2[string, int]
+		withPrefix iter.Seq2[string, int]
+		between    iter.Seq2[string, int]
+		keys       iter.Seq[string]
+		values     iter.Seq[int]
+	}
+	expected := seqs{"Tree", r.All(), r.Backward(), r.WithPrefix("sh"), r.Between("sells", "shore"), r.Keys(), r.Values()}
+	tests := []seqs{
+		{"SyncTree", s.All(), s.Backward(), s.WithPrefix("sh"), s.Between("sells", "shore"), s.Keys(), s.Values()},
+		{"ImmutableTree", im.All(), im.Backward(), im.WithPrefix("sh"), im.Between("sells", "shore"), im.Keys(), im.Values()},
+	}
+	for _, tt := range tests {
+		pairs := []struct {
+			name     string
+			expected iter.Seq2[string, int]
+			got      iter.Seq2[string, int]
+		}{
+			{"All", expected.all, tt.all},
+			{"Backward", expected.backward, tt.backward},
+			{"WithPrefix", expected.withPrefix, tt.withPrefix},
+			{"Between", expected.between, tt.between},
+		}
+		for _, p := range pairs {
+			if e, g := maps.Collect(p.expected), maps.Collect(p.got); reflect.DeepEqual(e, g) == false {
+				t.Fatalf("%s.%s(): expected=%v, got=%v", tt.name, p.name, e, g)
+			}
+			if e, g := slices.Collect(seqKeys(p.expected)), slices.Collect(seqKeys(p.got)); reflect.DeepEqual(e, g) == false {
+				t.Fatalf("%s.%s(): expected=%v, got=%v", tt.name, p.name, e, g)
+			}
+		}
+		if e, g := slices.Collect(expected.keys), slices.Collect(tt.keys); reflect.DeepEqual(e, g) == false {
+			t.Fatalf("%s.Keys(): expected=%v, got=%v", tt.name, e, g)
+		}
+		if e, g := slices.Collect(expected.values), slices.Collect(tt.values); reflect.DeepEqual(e, g) == false {
+			t.Fatalf("%s.Values(): expected=%v, got=%v", tt.name, e, g)
+		}
+	}
+
+	// the loop body may mutate SyncTree, the loop continues on the snapshot
+	count := 0
+	for k := range s.All() {
+		s.Delete(k)
+		count++
+	}
+	if count != len(m) || s.Len() != 0 {
+		t.Fatalf("expected=%v 0, got=%v %v", len(m), count, s.Len())
+	}
+}
+
+// returns the keys of the sequence in order
+func seqKeys(seq iter.Seq2[string, int]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
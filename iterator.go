@@ -0,0 +1,276 @@
+package radix
+
+//
+// Iterator walks the key-value pairs of the Tree in order, forward and backward.
+//
+// The edges of every node are kept sorted, and the key of a node is a prefix of the keys of its children,
+// so the pre-order traversal of the tree gives the keys in ascending order.
+// Iterator remembers the path from the root node to the current node as a stack,
+// so it can be paused, resumed and moved in both directions.
+//
+// Iterator must not be used after the tree is modified.
+// The Iterator of ImmutableTree is always valid, the tree is never modified.
+//
+//   it := t.Iterator()
+//   for ok := it.SeekGE("sh"); ok; ok = it.Next() {
+//       fmt.Println(it.Key(), it.Value())
+//   }
+//
+
+// Iterator definition
+type Iterator[V any] struct {
+	root  *node[V]
+	stack []iteratorFrame[V] // path from the root node to the current node
+}
+
+// iteratorFrame definition, node and the index of the edge from its parent
+type iteratorFrame[V any] struct {
+	node  *node[V]
+	index int // index of the edge in the parent node, -1 for the root node
+}
+
+// Iterator() returns a new unpositioned Iterator
+func (t *TypedTree[V]) Iterator() *Iterator[V] {
+	return &Iterator[V]{
+		root: t.root,
+	}
+}
+
+// Valid() returns true if the iterator is positioned at a key-value pair
+func (it *Iterator[V]) Valid() bool {
+	return len(it.stack) > 0
+}
+
+// Key() returns the key at the current position, or empty string if it is not valid
+func (it *Iterator[V]) Key() string {
+	if !it.Valid() {
+		return ""
+	}
+	return it.top().leaf.key
+}
+
+// Value() returns the value at the current position, or zero value if it is not valid
+func (it *Iterator[V]) Value() V {
+	if !it.Valid() {
+		var zero V
+		return zero
+	}
+	return it.top().leaf.value
+}
+
+// First() moves to the smallest key, returns false if the tree is empty
+func (it *Iterator[V]) First() bool {
+	it.reset()
+	return it.first()
+}
+
+// Last() moves to the largest key, returns false if the tree is empty
+func (it *Iterator[V]) Last() bool {
+	it.reset()
+	return it.last()
+}
+
+// SeekGE() moves to the smallest key which is greater than or equal to the given key
+func (it *Iterator[V]) SeekGE(key string) bool {
+	it.reset()
+
+	searches := []rune(key)
+	for {
+		n := it.top()
+
+		// the key of n equals to the search key, n and all the nodes below are greater than or equal to it
+		if len(searches) == 0 {
+			return it.first()
+		}
+
+		index := n.edgeIndex(searches[0])
+
+		// all the nodes below n are smaller than the search key
+		if index == len(n.edges) {
+			return it.skip()
+		}
+
+		child := n.edges[index].node
+		it.push(child, index)
+
+		// all the nodes below the child are greater than the search key
+		if n.edges[index].label != searches[0] {
+			return it.first()
+		}
+
+		commonLen := commonLength(searches, child.prefixes)
+		if commonLen == len(child.prefixes) {
+			searches = searches[commonLen:]
+			continue
+		}
+
+		// the search key is a prefix of the child's prefixes, or the child is greater
+		if commonLen == len(searches) || child.prefixes[commonLen] > searches[commonLen] {
+			return it.first()
+		}
+
+		// the child is smaller than the search key
+		return it.skip()
+	}
+}
+
+// SeekLT() moves to the largest key which is less than the given key
+func (it *Iterator[V]) SeekLT(key string) bool {
+	it.reset()
+
+	searches := []rune(key)
+	for {
+		n := it.top()
+
+		// the key of n equals to the search key, so go back before n
+		if len(searches) == 0 {
+			return it.back()
+		}
+
+		index := n.edgeIndex(searches[0])
+
+		// all the nodes below n are smaller than the search key
+		if index == len(n.edges) {
+			return it.last()
+		}
+
+		child := n.edges[index].node
+
+		// all the nodes below the child are greater than the search key
+		if n.edges[index].label != searches[0] {
+			return it.before(index)
+		}
+
+		commonLen := commonLength(searches, child.prefixes)
+		if commonLen == len(child.prefixes) {
+			it.push(child, index)
+			searches = searches[commonLen:]
+			continue
+		}
+
+		// the search key is a prefix of the child's prefixes, or the child is greater
+		if commonLen == len(searches) || child.prefixes[commonLen] > searches[commonLen] {
+			return it.before(index)
+		}
+
+		// the child is smaller than the search key
+		it.push(child, index)
+		return it.last()
+	}
+}
+
+// Next() moves to the next key, returns false if there is no more key
+func (it *Iterator[V]) Next() bool {
+	if !it.Valid() {
+		return false
+	}
+
+	// the children are greater than the current node
+	if n := it.top(); len(n.edges) > 0 {
+		it.push(n.edges[0].node, 0)
+		return it.first()
+	}
+
+	return it.skip()
+}
+
+// Prev() moves to the previous key, returns false if there is no more key
+func (it *Iterator[V]) Prev() bool {
+	if !it.Valid() {
+		return false
+	}
+	return it.back()
+}
+
+func (it *Iterator[V]) reset() {
+	it.stack = append(it.stack[:0], iteratorFrame[V]{node: it.root, index: -1})
+}
+
+func (it *Iterator[V]) top() *node[V] {
+	return it.stack[len(it.stack)-1].node
+}
+
+func (it *Iterator[V]) push(n *node[V], index int) {
+	it.stack = append(it.stack, iteratorFrame[V]{node: n, index: index})
+}
+
+func (it *Iterator[V]) pop() iteratorFrame[V] {
+	f := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	return f
+}
+
+// move to the smallest leaf under the current node, including itself
+func (it *Iterator[V]) first() bool {
+	for {
+		n := it.top()
+		if n.isLeaf() {
+			return true
+		}
+		if len(n.edges) == 0 {
+			// empty root node
+			it.stack = it.stack[:0]
+			return false
+		}
+		it.push(n.edges[0].node, 0)
+	}
+}
+
+// move to the largest leaf under the current node, including itself
+func (it *Iterator[V]) last() bool {
+	for {
+		n := it.top()
+		if num := len(n.edges); num > 0 {
+			it.push(n.edges[num-1].node, num-1)
+			continue
+		}
+		if n.isLeaf() {
+			return true
+		}
+		// empty root node
+		it.stack = it.stack[:0]
+		return false
+	}
+}
+
+// move to the smallest leaf after all the nodes under the current node
+func (it *Iterator[V]) skip() bool {
+	for len(it.stack) > 1 {
+		f := it.pop()
+		parent := it.top()
+		if f.index+1 < len(parent.edges) {
+			it.push(parent.edges[f.index+1].node, f.index+1)
+			return it.first()
+		}
+	}
+	it.stack = it.stack[:0]
+	return false
+}
+
+// move to the largest leaf before the current node
+func (it *Iterator[V]) back() bool {
+	for len(it.stack) > 1 {
+		f := it.pop()
+		if f.index > 0 {
+			return it.before(f.index)
+		}
+		if it.top().isLeaf() {
+			return true
+		}
+	}
+	it.stack = it.stack[:0]
+	return false
+}
+
+// move to the largest leaf before the index-th edge of the current node
+func (it *Iterator[V]) before(index int) bool {
+	n := it.top()
+	if index > 0 {
+		it.push(n.edges[index-1].node, index-1)
+		return it.last()
+	}
+	if n.isLeaf() {
+		return true
+	}
+	return it.back()
+}
@@ -0,0 +1,201 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	keys := []string{
+		"",
+		"あ",
+		"あい",
+		"あいう",
+		"あかさたな",
+		"romane",
+		"romanus",
+		"romulus",
+		"rubens",
+		"ruber",
+		"rubicon",
+		"rubicundus",
+	}
+
+	r := NewTree[int]()
+	for i, key := range keys {
+		r.Insert(key, i)
+	}
+
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+
+	// forward
+	got := []string{}
+	it := r.Iterator()
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, it.Key())
+	}
+	if reflect.DeepEqual(got, sorted) == false {
+		t.Fatalf("expected=%v, got=%v", sorted, got)
+	}
+
+	// backward
+	got = []string{}
+	for ok := it.Last(); ok; ok = it.Prev() {
+		got = append([]string{it.Key()}, got...)
+	}
+	if reflect.DeepEqual(got, sorted) == false {
+		t.Fatalf("expected=%v, got=%v", sorted, got)
+	}
+
+	tests := []struct {
+		input string
+		ge    string
+		lt    string
+	}{
+		{"", "", "-"},
+		{"a", "romane", ""},
+		{"roman", "romane", ""},
+		{"romanf", "romanus", "romane"},
+		{"romanus", "romanus", "romane"},
+		{"rub", "rubens", "romulus"},
+		{"rubi", "rubicon", "ruber"},
+		{"rubz", "あ", "rubicundus"},
+		{"あいうえお", "あかさたな", "あいう"},
+		{"か", "-", "あかさたな"},
+	}
+
+	for _, tt := range tests {
+		key := "-"
+		if it.SeekGE(tt.input) {
+			key = it.Key()
+		}
+		if key != tt.ge {
+			t.Fatalf("SeekGE(%q): expected=%q, got=%q", tt.input, tt.ge, key)
+		}
+
+		key = "-"
+		if it.SeekLT(tt.input) {
+			key = it.Key()
+		}
+		if key != tt.lt {
+			t.Fatalf("SeekLT(%q): expected=%q, got=%q", tt.input, tt.lt, key)
+		}
+	}
+
+	// value at the position
+	if it.SeekGE("rubicon"); it.Value() != 10 {
+		t.Fatalf("expected=%v, got=%v", 10, it.Value())
+	}
+}
+
+func TestIteratorEmpty(t *testing.T) {
+	it := NewTree[int]().Iterator()
+	if it.First() || it.Last() || it.SeekGE("") || it.SeekLT("a") || it.Next() || it.Prev() {
+		t.Fatalf("empty tree must not be valid")
+	}
+	if it.Valid() || it.Key() != "" || it.Value() != 0 {
+		t.Fatalf("unexpected key-value pair %q %v", it.Key(), it.Value())
+	}
+}
+
+func TestIteratorRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	r := NewTree[int]()
+	for i := 0; i < 2000; i++ {
+		r.Insert(fmt.Sprintf("%o", rnd.Intn(100000)), i)
+	}
+
+	sorted := r.CollectKeys("")
+	if sort.StringsAreSorted(sorted) == false {
+		t.Fatalf("keys are not sorted")
+	}
+
+	it := r.Iterator()
+	for i := 0; i < 2000; i++ {
+		input := fmt.Sprintf("%o", rnd.Intn(100000))
+
+		// SeekGE and then Next, up to 10 keys
+		index := sort.SearchStrings(sorted, input)
+		ok := it.SeekGE(input)
+		for n := 0; n < 10 && index+n < len(sorted); n++ {
+			if !ok || it.Key() != sorted[index+n] {
+				t.Fatalf("SeekGE(%q)+%v: expected=%q, got=%q", input, n, sorted[index+n], it.Key())
+			}
+			ok = it.Next()
+		}
+
+		// SeekLT and then Prev, up to 10 keys
+		index = sort.SearchStrings(sorted, input) - 1
+		ok = it.SeekLT(input)
+		for n := 0; n < 10 && index-n >= 0; n++ {
+			if !ok || it.Key() != sorted[index-n] {
+				t.Fatalf("SeekLT(%q)-%v: expected=%q, got=%q", input, n, sorted[index-n], it.Key())
+			}
+			ok = it.Prev()
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	keys := []string{
+		"2022-01-01",
+		"2022-01-15",
+		"2022-02-01",
+		"2022-02-28",
+		"2022-03-01",
+		"2023-01-01",
+	}
+
+	r := NewTree[int]()
+	for i, key := range keys {
+		r.Insert(key, i)
+	}
+
+	tests := []struct {
+		lo       string
+		hi       string
+		expected []string
+	}{
+		{"2022-01", "2022-02", []string{"2022-01-01", "2022-01-15"}},
+		{"2022-01-15", "2022-03-01", []string{"2022-01-15", "2022-02-01", "2022-02-28"}},
+		{"2022", "2023", []string{"2022-01-01", "2022-01-15", "2022-02-01", "2022-02-28", "2022-03-01"}},
+		{"2022-04", "2022-12", []string{}},
+		{"2023", "2022", []string{}},
+		{"", "~", keys},
+	}
+
+	for _, tt := range tests {
+		got := []string{}
+		r.Range(tt.lo, tt.hi, func(k string, v int) bool {
+			got = append(got, k)
+			return false
+		})
+		if reflect.DeepEqual(got, tt.expected) == false {
+			t.Fatalf("Range(%q, %q): expected=%v, got=%v", tt.lo, tt.hi, tt.expected, got)
+		}
+
+		reversed := []string{}
+		r.RangeReverse(tt.lo, tt.hi, func(k string, v int) bool {
+			reversed = append([]string{k}, reversed...)
+			return false
+		})
+		if reflect.DeepEqual(reversed, tt.expected) == false {
+			t.Fatalf("RangeReverse(%q, %q): expected=%v, got=%v", tt.lo, tt.hi, tt.expected, reversed)
+		}
+	}
+
+	// stop when the callback returns true
+	count := 0
+	r.Range("", "~", func(k string, v int) bool {
+		count++
+		return count == 2
+	})
+	if count != 2 {
+		t.Fatalf("expected count=%v, got=%v", 2, count)
+	}
+}
@@ -0,0 +1,354 @@
+package radix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"time"
+)
+
+//
+// Reader of the BGP routing table snapshots in MRT format (RFC 6396),
+// such as RouteViews and RIPE RIS "bview" / "rib" files.
+//
+// Only TABLE_DUMP_V2 records are read, the other records are skipped.
+//
+//   PEER_INDEX_TABLE    the list of the BGP peers, must come first
+//   RIB_IPV4_UNICAST    a prefix and the routes from the peers
+//   RIB_IPV6_UNICAST
+//
+// The files are usually compressed, wrap the file with gzip.NewReader() or bzip2.NewReader().
+//
+//   f, _ := os.Open("rib.20240101.0000.bz2")
+//   table, _ := LoadMRT(bzip2.NewReader(f))
+//   _, routes, _ := table.Lookup(netip.MustParseAddr("8.8.8.8"))
+//   routes[0].OriginAS // 15169
+//
+
+const (
+	mrtTableDumpV2 = 13
+
+	mrtPeerIndexTable = 1
+	mrtRIBIPv4Unicast = 2
+	mrtRIBIPv6Unicast = 4
+
+	bgpAttrNextHop     = 3
+	bgpAttrASPath      = 2
+	bgpAttrMPReachNLRI = 14
+
+	bgpASSet      = 1
+	bgpASSequence = 2
+
+	// the largest record to read, RIB records of the full table are far smaller
+	mrtMaxRecordLength = 16 << 20
+)
+
+var (
+	errMRTTruncated = errors.New("radix: mrt: truncated record")
+	errMRTTooLarge  = errors.New("radix: mrt: too large record, the input may be compressed")
+)
+
+// BGPPeer definition, an entry of PEER_INDEX_TABLE
+type BGPPeer struct {
+	BGPID netip.Addr
+	Addr  netip.Addr
+	AS    uint32
+}
+
+// BGPRoute definition, a route to the prefix received from the peer
+type BGPRoute struct {
+	Peer       BGPPeer
+	Originated time.Time
+	NextHop    netip.Addr
+	ASPath     []uint32 // the members of AS_SET are included in the order they appear
+	OriginAS   uint32   // the last AS of the AS_PATH, 0 if unknown
+}
+
+// MRTReader definition
+type MRTReader struct {
+	r     *bufio.Reader
+	peers []BGPPeer
+	buf   []byte
+}
+
+// Constructor
+// NewMRTReader() returns MRTReader which reads the records from r
+func NewMRTReader(r io.Reader) *MRTReader {
+	return &MRTReader{
+		r: bufio.NewReaderSize(r, 1<<16),
+	}
+}
+
+// Peers() returns the peers read from the PEER_INDEX_TABLE
+func (r *MRTReader) Peers() []BGPPeer {
+	return r.peers
+}
+
+// Next() returns the prefix and its routes of the next RIB record.
+// At the end of the input, returns io.EOF.
+func (r *MRTReader) Next() (netip.Prefix, []BGPRoute, error) {
+	for {
+		var header [12]byte
+		if _, err := io.ReadFull(r.r, header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = errMRTTruncated
+			}
+			return netip.Prefix{}, nil, err
+		}
+		typ := binary.BigEndian.Uint16(header[4:6])
+		subtype := binary.BigEndian.Uint16(header[6:8])
+		length := int64(binary.BigEndian.Uint32(header[8:12]))
+
+		// the other records are skipped without buffering, so they may be large
+		if typ != mrtTableDumpV2 {
+			if _, err := io.CopyN(io.Discard, r.r, length); err != nil {
+				return netip.Prefix{}, nil, errMRTTruncated
+			}
+			continue
+		}
+
+		if length > mrtMaxRecordLength {
+			return netip.Prefix{}, nil, fmt.Errorf("%w: %d bytes", errMRTTooLarge, length)
+		}
+		if int64(cap(r.buf)) < length {
+			r.buf = make([]byte, length)
+		}
+		body := r.buf[:length]
+		if _, err := io.ReadFull(r.r, body); err != nil {
+			return netip.Prefix{}, nil, errMRTTruncated
+		}
+
+		switch subtype {
+		case mrtPeerIndexTable:
+			if err := r.readPeerIndexTable(body); err != nil {
+				return netip.Prefix{}, nil, err
+			}
+		case mrtRIBIPv4Unicast, mrtRIBIPv6Unicast:
+			return r.readRIB(body, subtype == mrtRIBIPv4Unicast)
+		}
+	}
+}
+
+// mrtDecoder reads the fields from the record, the first error is kept and the rest fields are zero
+type mrtDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *mrtDecoder) bytes(n int) []byte {
+	if d.err != nil || n > len(d.buf) {
+		d.err = errMRTTruncated
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *mrtDecoder) uint8() uint8 {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *mrtDecoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *mrtDecoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// reads IPv4 address if is4, otherwise IPv6 address
+func (d *mrtDecoder) addr(is4 bool) netip.Addr {
+	if is4 {
+		if b := d.bytes(4); b != nil {
+			return netip.AddrFrom4([4]byte(b))
+		}
+		return netip.Addr{}
+	}
+	if b := d.bytes(16); b != nil {
+		return netip.AddrFrom16([16]byte(b))
+	}
+	return netip.Addr{}
+}
+
+func (r *MRTReader) readPeerIndexTable(body []byte) error {
+	d := &mrtDecoder{buf: body}
+	d.addr(true) // collector BGP ID
+	d.bytes(int(d.uint16()))
+
+	count := int(d.uint16())
+	peers := make([]BGPPeer, 0, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		peerType := d.uint8()
+		peer := BGPPeer{BGPID: d.addr(true)}
+		peer.Addr = d.addr(peerType&0x01 == 0)
+		if peerType&0x02 != 0 {
+			peer.AS = d.uint32()
+		} else {
+			peer.AS = uint32(d.uint16())
+		}
+		peers = append(peers, peer)
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	r.peers = peers
+	return nil
+}
+
+func (r *MRTReader) readRIB(body []byte, is4 bool) (netip.Prefix, []BGPRoute, error) {
+	if r.peers == nil {
+		return netip.Prefix{}, nil, errors.New("radix: mrt: RIB record before PEER_INDEX_TABLE")
+	}
+
+	d := &mrtDecoder{buf: body}
+	d.uint32() // sequence number
+
+	bitLen := int(d.uint8())
+	maxLen := 128
+	if is4 {
+		maxLen = 32
+	}
+	if bitLen > maxLen {
+		return netip.Prefix{}, nil, fmt.Errorf("radix: mrt: invalid prefix length %d", bitLen)
+	}
+	prefix := bitsToPrefix(d.bytes((bitLen+7)/8), bitLen, is4).Masked()
+
+	count := int(d.uint16())
+	routes := make([]BGPRoute, 0, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		index := int(d.uint16())
+		if index >= len(r.peers) {
+			return prefix, nil, fmt.Errorf("radix: mrt: peer index %d out of %d peers", index, len(r.peers))
+		}
+
+		route := BGPRoute{
+			Peer:       r.peers[index],
+			Originated: time.Unix(int64(d.uint32()), 0),
+		}
+		attrs := d.bytes(int(d.uint16()))
+		if d.err == nil {
+			if err := route.readAttributes(attrs); err != nil {
+				return prefix, nil, err
+			}
+		}
+		routes = append(routes, route)
+	}
+	if d.err != nil {
+		return prefix, nil, d.err
+	}
+
+	return prefix, routes, nil
+}
+
+// reads the BGP path attributes which are needed for BGPRoute
+func (route *BGPRoute) readAttributes(attrs []byte) error {
+	d := &mrtDecoder{buf: attrs}
+	for len(d.buf) > 0 && d.err == nil {
+		flags := d.uint8()
+		typ := d.uint8()
+		length := 0
+		if flags&0x10 != 0 {
+			length = int(d.uint16()) // extended length
+		} else {
+			length = int(d.uint8())
+		}
+		value := d.bytes(length)
+		if d.err != nil {
+			break
+		}
+
+		switch typ {
+		case bgpAttrASPath:
+			if err := route.readASPath(value); err != nil {
+				return err
+			}
+		case bgpAttrNextHop:
+			if len(value) == 4 {
+				route.NextHop = netip.AddrFrom4([4]byte(value))
+			}
+		case bgpAttrMPReachNLRI:
+			route.readMPReachNextHop(value)
+		}
+	}
+	return d.err
+}
+
+// reads AS_PATH, TABLE_DUMP_V2 always has 4-octet AS numbers
+func (route *BGPRoute) readASPath(value []byte) error {
+	d := &mrtDecoder{buf: value}
+	path := []uint32{}
+	lastType := uint8(0)
+	lastLen := 0
+	for len(d.buf) > 0 && d.err == nil {
+		lastType = d.uint8()
+		lastLen = int(d.uint8())
+		for i := 0; i < lastLen; i++ {
+			path = append(path, d.uint32())
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	route.ASPath = path
+	// the origin of AS_SET is ambiguous unless the set has only one member
+	if len(path) > 0 && (lastType == bgpASSequence || (lastType == bgpASSet && lastLen == 1)) {
+		route.OriginAS = path[len(path)-1]
+	}
+	return nil
+}
+
+// reads the next hop of MP_REACH_NLRI.
+// RFC 6396 abbreviates the attribute to the next hop length and the next hop,
+// but some implementations write the full attribute.
+func (route *BGPRoute) readMPReachNextHop(value []byte) {
+	if len(value) > 0 && int(value[0]) != len(value)-1 && len(value) > 3 {
+		value = value[3:] // AFI and SAFI
+	}
+	if len(value) == 0 || int(value[0]) > len(value)-1 {
+		return
+	}
+	switch value[0] {
+	case 4:
+		route.NextHop = netip.AddrFrom4([4]byte(value[1:5]))
+	case 16, 32:
+		// the global address comes first, the link local address may follow
+		route.NextHop = netip.AddrFrom16([16]byte(value[1:17]))
+	}
+}
+
+// LoadMRT() reads all RIB records and returns the PrefixTable of the routes.
+// The routes to the same prefix are in the order of the RIB entries.
+func LoadMRT(r io.Reader) (*PrefixTable[[]BGPRoute], error) {
+	table := NewPrefixTable[[]BGPRoute]()
+	reader := NewMRTReader(r)
+	for {
+		prefix, routes, err := reader.Next()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return table, err
+		}
+
+		// the same prefix may be split into several records
+		if existing, ok := table.Get(prefix); ok {
+			routes = append(existing, routes...)
+		}
+		table.Insert(prefix, routes)
+	}
+}
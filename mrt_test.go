@@ -0,0 +1,275 @@
+package radix
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"net/netip"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helpers to build MRT records
+
+func buildMRTRecord(typ, subtype uint16, body []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, 1700000000)
+	b = binary.BigEndian.AppendUint16(b, typ)
+	b = binary.BigEndian.AppendUint16(b, subtype)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+func buildPeerIndexTable(peers []BGPPeer) []byte {
+	b := netip.MustParseAddr("192.0.2.1").AsSlice()
+	b = binary.BigEndian.AppendUint16(b, 4)
+	b = append(b, "view"...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(peers)))
+	for i, p := range peers {
+		// use 2-octet AS for the even peers
+		peerType := byte(0)
+		if p.Addr.Is6() {
+			peerType |= 0x01
+		}
+		if i%2 == 1 {
+			peerType |= 0x02
+		}
+		b = append(b, peerType)
+		b = append(b, p.BGPID.AsSlice()...)
+		b = append(b, p.Addr.AsSlice()...)
+		if i%2 == 1 {
+			b = binary.BigEndian.AppendUint32(b, p.AS)
+		} else {
+			b = binary.BigEndian.AppendUint16(b, uint16(p.AS))
+		}
+	}
+	return buildMRTRecord(mrtTableDumpV2, mrtPeerIndexTable, b)
+}
+
+type ribEntry struct {
+	peer    int
+	path    [][]uint32 // segments, the segment with one AS is AS_SET if set is true
+	set     bool
+	nextHop netip.Addr
+}
+
+func buildAttribute(typ byte, value []byte) []byte {
+	if len(value) > 255 {
+		b := []byte{0x50, typ}
+		b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+		return append(b, value...)
+	}
+	return append([]byte{0x40, typ, byte(len(value))}, value...)
+}
+
+func buildRIB(seq uint32, prefix netip.Prefix, entries []ribEntry) []byte {
+	b := binary.BigEndian.AppendUint32(nil, seq)
+	b = append(b, byte(prefix.Bits()))
+	b = append(b, prefix.Addr().AsSlice()[:(prefix.Bits()+7)/8]...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(entries)))
+	for _, e := range entries {
+		attrs := buildAttribute(1, []byte{0}) // ORIGIN IGP
+
+		path := []byte{}
+		for i, seg := range e.path {
+			segType := byte(bgpASSequence)
+			if e.set && i == len(e.path)-1 {
+				segType = bgpASSet
+			}
+			path = append(path, segType, byte(len(seg)))
+			for _, as := range seg {
+				path = binary.BigEndian.AppendUint32(path, as)
+			}
+		}
+		attrs = append(attrs, buildAttribute(bgpAttrASPath, path)...)
+
+		if e.nextHop.Is4() {
+			attrs = append(attrs, buildAttribute(bgpAttrNextHop, e.nextHop.AsSlice())...)
+		} else {
+			attrs = append(attrs, buildAttribute(bgpAttrMPReachNLRI, append([]byte{16}, e.nextHop.AsSlice()...))...)
+		}
+
+		b = binary.BigEndian.AppendUint16(b, uint16(e.peer))
+		b = binary.BigEndian.AppendUint32(b, 1600000000)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(attrs)))
+		b = append(b, attrs...)
+	}
+
+	subtype := uint16(mrtRIBIPv6Unicast)
+	if prefix.Addr().Is4() {
+		subtype = mrtRIBIPv4Unicast
+	}
+	return buildMRTRecord(mrtTableDumpV2, subtype, b)
+}
+
+func TestLoadMRT(t *testing.T) {
+	p := netip.MustParsePrefix
+	a := netip.MustParseAddr
+
+	peers := []BGPPeer{
+		{BGPID: a("198.51.100.1"), Addr: a("198.51.100.1"), AS: 64500},
+		{BGPID: a("198.51.100.2"), Addr: a("2001:db8::2"), AS: 4200000000},
+	}
+
+	long := make([]uint32, 80) // AS_PATH longer than 255 bytes
+	for i := range long {
+		long[i] = 64500
+	}
+	long[len(long)-1] = 15169
+
+	var b bytes.Buffer
+	b.Write(buildMRTRecord(16, 4, []byte("BGP4MP message is skipped")))
+	b.Write(buildMRTRecord(16, 4, make([]byte, mrtMaxRecordLength+1))) // large record is skipped too
+	b.Write(buildPeerIndexTable(peers))
+	b.Write(buildRIB(0, p("8.8.8.0/24"), []ribEntry{
+		{peer: 0, path: [][]uint32{{64500, 3356, 15169}}, nextHop: a("198.51.100.1")},
+		{peer: 1, path: [][]uint32{long}, nextHop: a("198.51.100.2")},
+	}))
+	b.Write(buildRIB(1, p("10.0.0.0/8"), []ribEntry{
+		{peer: 0, path: [][]uint32{{64500}, {64501, 64502}}, set: true, nextHop: a("198.51.100.1")},
+	}))
+	b.Write(buildRIB(2, p("0.0.0.0/0"), []ribEntry{
+		{peer: 0, path: [][]uint32{}, nextHop: a("198.51.100.1")},
+	}))
+	b.Write(buildRIB(3, p("2001:4860::/32"), []ribEntry{
+		{peer: 1, path: [][]uint32{{4200000000, 15169}}, nextHop: a("2001:db8::2")},
+	}))
+	b.Write(buildRIB(4, p("8.8.8.0/24"), []ribEntry{
+		{peer: 0, path: [][]uint32{{64500, 174}, {15169}}, set: true, nextHop: a("198.51.100.1")},
+	}))
+
+	table, err := LoadMRT(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Len() != 4 {
+		t.Fatalf("expected=4, got=%v", table.Len())
+	}
+
+	prefix, routes, ok := table.Lookup(a("8.8.8.8"))
+	if !ok || prefix != p("8.8.8.0/24") || len(routes) != 3 {
+		t.Fatalf("unexpected lookup result %v %v", prefix, routes)
+	}
+	expected := BGPRoute{
+		Peer:       peers[0],
+		Originated: time.Unix(1600000000, 0),
+		NextHop:    a("198.51.100.1"),
+		ASPath:     []uint32{64500, 3356, 15169},
+		OriginAS:   15169,
+	}
+	if !reflect.DeepEqual(routes[0], expected) {
+		t.Fatalf("expected=%+v, got=%+v", expected, routes[0])
+	}
+	if routes[1].OriginAS != 15169 || len(routes[1].ASPath) != 80 || routes[1].Peer.AS != 4200000000 {
+		t.Fatalf("unexpected route %+v", routes[1])
+	}
+	// AS_SET with one member is the origin
+	if routes[2].OriginAS != 15169 || !reflect.DeepEqual(routes[2].ASPath, []uint32{64500, 174, 15169}) {
+		t.Fatalf("unexpected route %+v", routes[2])
+	}
+
+	// AS_SET with two members has no origin
+	_, routes, _ = table.Lookup(a("10.1.1.1"))
+	if routes[0].OriginAS != 0 || !reflect.DeepEqual(routes[0].ASPath, []uint32{64500, 64501, 64502}) {
+		t.Fatalf("unexpected route %+v", routes[0])
+	}
+
+	// empty AS_PATH, the route originated by the peer
+	_, routes, _ = table.Lookup(a("1.1.1.1"))
+	if routes[0].OriginAS != 0 || len(routes[0].ASPath) != 0 {
+		t.Fatalf("unexpected route %+v", routes[0])
+	}
+
+	_, routes, _ = table.Lookup(a("2001:4860:4860::8888"))
+	if routes[0].OriginAS != 15169 || routes[0].NextHop != a("2001:db8::2") || routes[0].Peer != peers[1] {
+		t.Fatalf("unexpected route %+v", routes[0])
+	}
+}
+
+func TestMRTReaderError(t *testing.T) {
+	peers := []BGPPeer{{BGPID: netip.MustParseAddr("198.51.100.1"), Addr: netip.MustParseAddr("198.51.100.1"), AS: 64500}}
+	rib := buildRIB(0, netip.MustParsePrefix("10.0.0.0/8"), []ribEntry{{peer: 0, path: [][]uint32{{64500}}, nextHop: netip.MustParseAddr("198.51.100.1")}})
+	badPeer := buildRIB(0, netip.MustParsePrefix("10.0.0.0/8"), []ribEntry{{peer: 1, path: [][]uint32{{64500}}, nextHop: netip.MustParseAddr("198.51.100.1")}})
+
+	// truncate the attributes in the body, and fix the length in the header
+	broken := append([]byte(nil), rib[:len(rib)-3]...)
+	binary.BigEndian.PutUint32(broken[8:12], uint32(len(broken)-12))
+
+	// the header of TABLE_DUMP_V2 record with the length of 4GiB
+	huge := buildMRTRecord(mrtTableDumpV2, mrtRIBIPv4Unicast, nil)
+	binary.BigEndian.PutUint32(huge[8:12], 0xffffffff)
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"no peer index table", rib},
+		{"truncated bzip2 header", []byte("BZh91AY&SY\x12\x34\x56\x78\x9a")}, // skipped as unknown type of 1.3GB
+		{"too large record", append(buildPeerIndexTable(peers), huge...)},
+		{"truncated skipped record", buildMRTRecord(16, 4, []byte("BGP4MP"))[:14]},
+		{"truncated header", append(buildPeerIndexTable(peers), rib[:5]...)},
+		{"truncated body", append(buildPeerIndexTable(peers), rib[:len(rib)-1]...)},
+		{"truncated attributes", append(buildPeerIndexTable(peers), broken...)},
+		{"peer index out of range", append(buildPeerIndexTable(peers), badPeer...)},
+	}
+	for _, tt := range tests {
+		_, err := LoadMRT(bytes.NewReader(tt.input))
+		if err == nil || err == io.EOF {
+			t.Fatalf("%v: expected error, got=%v", tt.name, err)
+		}
+		if strings.HasPrefix(tt.name, "truncated") && !errors.Is(err, errMRTTruncated) {
+			t.Fatalf("%v: expected=%v, got=%v", tt.name, errMRTTruncated, err)
+		}
+		if strings.HasPrefix(tt.name, "too large") && !errors.Is(err, errMRTTooLarge) {
+			t.Fatalf("%v: expected=%v, got=%v", tt.name, errMRTTooLarge, err)
+		}
+	}
+}
+
+// go test -bench MRT -benchmem -run none with RADIX_MRT_FILE=/path/to/rib.bz2
+func BenchmarkMRTLookup(b *testing.B) {
+	name := os.Getenv("RADIX_MRT_FILE")
+	if name == "" {
+		b.Skip("RADIX_MRT_FILE is not set")
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch {
+	case strings.HasSuffix(name, ".bz2"):
+		r = bzip2.NewReader(f)
+	case strings.HasSuffix(name, ".gz"):
+		if r, err = gzip.NewReader(f); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	table, err := LoadMRT(r)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("%d prefixes", table.Len())
+
+	rnd := rand.New(rand.NewSource(1))
+	addrs := make([]netip.Addr, 1000)
+	for i := range addrs {
+		addrs[i] = netip.AddrFrom4([4]byte{byte(rnd.Intn(224)), byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256))})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table.Lookup(addrs[i%len(addrs)])
+	}
+}
@@ -0,0 +1,211 @@
+package radix
+
+import (
+	"net/netip"
+	"sort"
+	"sync/atomic"
+)
+
+//
+// MultiTable holds many independent PrefixTables identified by TableID, such as VRFs.
+// The tables are created on the first Insert() or Leak().
+//
+// A prefix can be leaked to another table.
+// When the longest match in a table is the leaked prefix,
+// the lookup continues in the target table with the same address.
+//
+//   table 1: 10.0.0.0/8   -> leak to table 2
+//            0.0.0.0/0    -> "gig1"
+//   table 2: 10.1.0.0/16  -> "gig2"
+//
+//   Lookup(1, 10.1.0.1)    => table 2, 10.1.0.0/16, "gig2"
+//   Lookup(1, 10.2.0.1)    => not found, the lookup does not come back to table 1
+//
+// MultiTable is not safe for concurrent use,
+// except that Lookup() can be called concurrently as long as no other method is called.
+//
+
+// TableID is the identifier of the table, such as VRF ID or Linux routing table number
+type TableID uint32
+
+// TableStats definition
+type TableStats struct {
+	Prefixes int    // number of prefixes, including leaked ones
+	Leaks    int    // number of leaked prefixes
+	Lookups  uint64 // number of Lookup() called on the table
+	Misses   uint64 // number of Lookup() which found nothing
+}
+
+// entry of the table, the value or the table to leak to
+type tableEntry[V any] struct {
+	value  V
+	leak   bool
+	target TableID
+}
+
+// subTable definition, one of the tables in MultiTable
+type subTable[V any] struct {
+	prefixes *PrefixTable[tableEntry[V]]
+	leaks    int
+	lookups  atomic.Uint64
+	misses   atomic.Uint64
+}
+
+// MultiTable definition
+type MultiTable[V any] struct {
+	tables map[TableID]*subTable[V]
+}
+
+// Constructor
+// NewMultiTable() returns empty MultiTable which stores values of type V
+func NewMultiTable[V any]() *MultiTable[V] {
+	return &MultiTable[V]{
+		tables: make(map[TableID]*subTable[V]),
+	}
+}
+
+// returns the table, create it if not exists
+func (m *MultiTable[V]) tableOf(id TableID) *subTable[V] {
+	t, ok := m.tables[id]
+	if !ok {
+		t = &subTable[V]{prefixes: NewPrefixTable[tableEntry[V]]()}
+		m.tables[id] = t
+	}
+	return t
+}
+
+// Tables() returns the IDs of the tables in ascending order
+func (m *MultiTable[V]) Tables() []TableID {
+	ids := make([]TableID, 0, len(m.tables))
+	for id := range m.tables {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// DeleteTable() deletes the table and all its prefixes.
+// The prefixes leaked to the table in other tables are kept, and the lookup through them finds nothing.
+// returns true if the table existed.
+func (m *MultiTable[V]) DeleteTable(id TableID) bool {
+	_, ok := m.tables[id]
+	delete(m.tables, id)
+	return ok
+}
+
+// Insert() adds the prefix and its value to the table.
+// returns true if newly inserted.
+// returns false if update existing prefix, or the prefix is invalid.
+func (m *MultiTable[V]) Insert(id TableID, p netip.Prefix, v V) bool {
+	return m.insert(id, p, tableEntry[V]{value: v})
+}
+
+// Leak() adds the prefix to the table which forwards the lookup to the target table.
+// returns true if newly inserted.
+// returns false if update existing prefix, or the prefix is invalid.
+func (m *MultiTable[V]) Leak(id TableID, p netip.Prefix, target TableID) bool {
+	return m.insert(id, p, tableEntry[V]{leak: true, target: target})
+}
+
+func (m *MultiTable[V]) insert(id TableID, p netip.Prefix, e tableEntry[V]) bool {
+	if !p.IsValid() {
+		return false
+	}
+	t := m.tableOf(id)
+	if old, ok := t.prefixes.Get(p); ok && old.leak {
+		t.leaks--
+	}
+	if e.leak {
+		t.leaks++
+	}
+	return t.prefixes.Insert(p, e)
+}
+
+// Get() returns the value of the prefix in the table.
+// If the prefix is not found or leaked to another table, returns zero value and false.
+func (m *MultiTable[V]) Get(id TableID, p netip.Prefix) (V, bool) {
+	if t, ok := m.tables[id]; ok {
+		if e, ok := t.prefixes.Get(p); ok && !e.leak {
+			return e.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete() deletes the prefix from the table, the value or the leak.
+// returns true if deleted.
+func (m *MultiTable[V]) Delete(id TableID, p netip.Prefix) bool {
+	t, ok := m.tables[id]
+	if !ok {
+		return false
+	}
+	e, ok := t.prefixes.Delete(p)
+	if ok && e.leak {
+		t.leaks--
+	}
+	return ok
+}
+
+// Walk() calls the callback function for each prefix of the table which has the value, the leaked prefixes are skipped.
+// The order is same as PrefixTable.Walk().
+func (m *MultiTable[V]) Walk(id TableID, fn PrefixWalkCallback[V]) {
+	t, ok := m.tables[id]
+	if !ok {
+		return
+	}
+	t.prefixes.Walk(func(p netip.Prefix, e tableEntry[V]) bool {
+		if e.leak {
+			return false
+		}
+		return fn(p, e.value)
+	})
+}
+
+// Lookup() returns the longest match of the address in the table, following the leaked prefixes.
+// It returns the table where the value is found, the prefix and the value.
+// If nothing found, or the leaks make a loop, returns false.
+func (m *MultiTable[V]) Lookup(id TableID, addr netip.Addr) (TableID, netip.Prefix, V, bool) {
+	var zero V
+	first, ok := m.tables[id]
+	if !ok {
+		return 0, netip.Prefix{}, zero, false
+	}
+	first.lookups.Add(1)
+
+	// a loop never visits more tables than exist
+	current := first
+	for hops := 0; hops <= len(m.tables); hops++ {
+		p, e, ok := current.prefixes.Lookup(addr)
+		if !ok {
+			break
+		}
+		if !e.leak {
+			return id, p, e.value, true
+		}
+
+		id = e.target
+		if current, ok = m.tables[id]; !ok {
+			break
+		}
+	}
+
+	first.misses.Add(1)
+	return 0, netip.Prefix{}, zero, false
+}
+
+// Stats() returns the statistics of the table.
+// If the table does not exist, returns zero TableStats.
+// Lookups and Misses are counted on the table given to Lookup(), not on the tables leaked to.
+func (m *MultiTable[V]) Stats(id TableID) TableStats {
+	t, ok := m.tables[id]
+	if !ok {
+		return TableStats{}
+	}
+	return TableStats{
+		Prefixes: t.prefixes.Len(),
+		Leaks:    t.leaks,
+		Lookups:  t.lookups.Load(),
+		Misses:   t.misses.Load(),
+	}
+}
@@ -0,0 +1,88 @@
+package radix
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestMultiTable(t *testing.T) {
+	p := netip.MustParsePrefix
+	a := netip.MustParseAddr
+
+	m := NewMultiTable[string]()
+	m.Insert(1, p("0.0.0.0/0"), "gig1")
+	m.Leak(1, p("10.0.0.0/8"), 2)
+	m.Insert(2, p("10.1.0.0/16"), "gig2")
+	m.Insert(2, p("2001:db8::/32"), "gig2")
+	m.Leak(2, p("10.2.0.0/16"), 3)
+	m.Leak(3, p("10.2.0.0/16"), 2) // loop
+	m.Insert(4, p("10.0.0.0/8"), "gig4")
+
+	if got := m.Tables(); !reflect.DeepEqual(got, []TableID{1, 2, 3, 4}) {
+		t.Fatalf("expected=%v, got=%v", []TableID{1, 2, 3, 4}, got)
+	}
+
+	tests := []struct {
+		table    TableID
+		addr     string
+		found    TableID
+		prefix   string
+		value    string
+		expected bool
+	}{
+		{1, "192.168.0.1", 1, "0.0.0.0/0", "gig1", true},
+		{1, "10.1.0.1", 2, "10.1.0.0/16", "gig2", true},
+		{1, "10.3.0.1", 0, "", "", false},
+		{1, "10.2.0.1", 0, "", "", false},
+		{2, "10.1.255.255", 2, "10.1.0.0/16", "gig2", true},
+		{2, "2001:db8::1", 2, "2001:db8::/32", "gig2", true},
+		{4, "10.1.0.1", 4, "10.0.0.0/8", "gig4", true},
+		{5, "10.1.0.1", 0, "", "", false},
+	}
+	for _, tt := range tests {
+		found, prefix, value, ok := m.Lookup(tt.table, a(tt.addr))
+		if ok != tt.expected {
+			t.Fatalf("table=%v, addr=%v, expected=%v, got=%v", tt.table, tt.addr, tt.expected, ok)
+		}
+		if ok && (found != tt.found || prefix != p(tt.prefix) || value != tt.value) {
+			t.Fatalf("expected=%v %v %v, got=%v %v %v", tt.found, tt.prefix, tt.value, found, prefix, value)
+		}
+	}
+
+	// the leaked prefix has no value
+	if _, ok := m.Get(1, p("10.0.0.0/8")); ok {
+		t.Fatalf("expected=false, got=true")
+	}
+	if v, _ := m.Get(2, p("10.1.0.0/16")); v != "gig2" {
+		t.Fatalf("expected=gig2, got=%v", v)
+	}
+
+	expected := TableStats{Prefixes: 2, Leaks: 1, Lookups: 4, Misses: 2}
+	if got := m.Stats(1); got != expected {
+		t.Fatalf("expected=%+v, got=%+v", expected, got)
+	}
+
+	// replace the leak with the value
+	m.Insert(1, p("10.0.0.0/8"), "gig1")
+	if got := m.Stats(1); got.Leaks != 0 || got.Prefixes != 2 {
+		t.Fatalf("unexpected stats %+v", got)
+	}
+	if !m.Delete(2, p("10.2.0.0/16")) || m.Delete(2, p("10.2.0.0/16")) {
+		t.Fatalf("unexpected delete result")
+	}
+	if got := m.Stats(2); got.Leaks != 0 || got.Prefixes != 2 {
+		t.Fatalf("unexpected stats %+v", got)
+	}
+
+	// deleting the table makes the leak to it a miss
+	if !m.DeleteTable(2) {
+		t.Fatalf("failed to delete table")
+	}
+	if _, _, _, ok := m.Lookup(3, a("10.2.0.1")); ok {
+		t.Fatalf("expected=false, got=true")
+	}
+	if got := m.Stats(2); got != (TableStats{}) {
+		t.Fatalf("expected zero stats, got=%+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package radix
+
+import (
+	"net/netip"
+)
+
+//
+// PrefixSet is a set of IP addresses represented by the prefixes.
+// The prefixes are always normalized, they do not overlap and no siblings are left unmerged,
+// so two sets with the same addresses have the same prefixes.
+//
+// Union(), Intersect() and Difference() return a new PrefixSet.
+// They walk the prefixes of one set and query the other with the longest match and the prefix walk,
+// so the cost is proportional to the number of the prefixes, not the number of the addresses.
+//
+//   allow := NewPrefixSet(netip.MustParsePrefix("10.0.0.0/8"))
+//   deny := NewPrefixSet(netip.MustParsePrefix("10.0.0.0/24"))
+//   allow.Difference(deny).Prefixes() // 10.0.1.0/24, 10.0.2.0/23, 10.0.4.0/22, ... 10.128.0.0/9
+//
+
+// PrefixSet definition
+type PrefixSet struct {
+	table *PrefixTable[struct{}]
+}
+
+// Constructor
+// NewPrefixSet() returns a new PrefixSet which contains the given prefixes, invalid prefixes are ignored.
+// To make a set from a PrefixTable, use NewPrefixSet(t.Aggregate()...).
+func NewPrefixSet(prefixes ...netip.Prefix) *PrefixSet {
+	t := NewPrefixTable[struct{}]()
+	for _, p := range prefixes {
+		t.Insert(p, struct{}{})
+	}
+	return normalizePrefixSet(t)
+}
+
+// returns a new PrefixSet which has the aggregated prefixes of t
+func normalizePrefixSet(t *PrefixTable[struct{}]) *PrefixSet {
+	normalized := NewPrefixTable[struct{}]()
+	for _, p := range t.Aggregate() {
+		normalized.Insert(p, struct{}{})
+	}
+	return &PrefixSet{table: normalized}
+}
+
+// Len() returns number of the normalized prefixes in the PrefixSet
+func (s *PrefixSet) Len() int {
+	return s.table.Len()
+}
+
+// Prefixes() returns the normalized prefixes, IPv4 first, then IPv6, sorted by address
+func (s *PrefixSet) Prefixes() []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	s.table.Walk(func(p netip.Prefix, v struct{}) bool {
+		prefixes = append(prefixes, p)
+		return false
+	})
+	return prefixes
+}
+
+// Contains() returns true if the address is in the PrefixSet
+func (s *PrefixSet) Contains(addr netip.Addr) bool {
+	_, _, ok := s.table.Lookup(addr)
+	return ok
+}
+
+// returns true if the whole prefix is in the PrefixSet
+func (s *PrefixSet) covers(p netip.Prefix) bool {
+	var buf [16]byte
+	return s.table.treeOf(p.Addr()).longestMatch(addrBytes(&buf, p.Addr()), p.Bits()) != nil
+}
+
+// returns true if some prefixes in the PrefixSet are inside the given prefix
+func (s *PrefixSet) overlaps(p netip.Prefix) bool {
+	var buf [16]byte
+	found := false
+	s.table.treeOf(p.Addr()).WalkPrefix(addrBytes(&buf, p.Addr()), p.Bits(), func(key []byte, bitLen int, v struct{}) bool {
+		found = true
+		return true
+	})
+	return found
+}
+
+// Union() returns a new PrefixSet which contains the addresses in s or other
+func (s *PrefixSet) Union(other *PrefixSet) *PrefixSet {
+	t := NewPrefixTable[struct{}]()
+	for _, set := range []*PrefixSet{s, other} {
+		set.table.Walk(func(p netip.Prefix, v struct{}) bool {
+			t.Insert(p, v)
+			return false
+		})
+	}
+	return normalizePrefixSet(t)
+}
+
+// Intersect() returns a new PrefixSet which contains the addresses in both s and other
+func (s *PrefixSet) Intersect(other *PrefixSet) *PrefixSet {
+	t := NewPrefixTable[struct{}]()
+	s.table.Walk(func(p netip.Prefix, v struct{}) bool {
+		// p is inside other, or some prefixes of other are inside p
+		if other.covers(p) {
+			t.Insert(p, v)
+			return false
+		}
+		for _, e := range other.table.CoveredBy(p) {
+			t.Insert(e.Prefix, v)
+		}
+		return false
+	})
+	return normalizePrefixSet(t)
+}
+
+// Difference() returns a new PrefixSet which contains the addresses in s but not in other
+func (s *PrefixSet) Difference(other *PrefixSet) *PrefixSet {
+	t := NewPrefixTable[struct{}]()
+	s.table.Walk(func(p netip.Prefix, v struct{}) bool {
+		for _, q := range other.subtract(p) {
+			t.Insert(q, v)
+		}
+		return false
+	})
+	return normalizePrefixSet(t)
+}
+
+// subtract() returns the prefixes which cover p except the addresses in s.
+// p is split into halves until each half is entirely in s or entirely out of s.
+func (s *PrefixSet) subtract(p netip.Prefix) []netip.Prefix {
+	if s.covers(p) {
+		return nil
+	}
+	if !s.overlaps(p) {
+		return []netip.Prefix{p}
+	}
+
+	lower, upper := splitPrefix(p)
+	return append(s.subtract(lower), s.subtract(upper)...)
+}
+
+// splitPrefix() returns the lower half and the upper half of the prefix
+func splitPrefix(p netip.Prefix) (netip.Prefix, netip.Prefix) {
+	var buf [16]byte
+	key := addrBytes(&buf, p.Addr())
+	bitLen := p.Bits()
+
+	lower := bitsToPrefix(maskBits(key, bitLen), bitLen+1, p.Addr().Is4())
+
+	upperKey := maskBits(key, bitLen+1)
+	upperKey[bitLen/8] |= 0x80 >> (bitLen % 8)
+	upper := bitsToPrefix(upperKey, bitLen+1, p.Addr().Is4())
+
+	return lower, upper
+}
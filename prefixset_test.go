@@ -0,0 +1,108 @@
+package radix
+
+import (
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func parsePrefixes(ss ...string) []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	for _, s := range ss {
+		prefixes = append(prefixes, netip.MustParsePrefix(s))
+	}
+	return prefixes
+}
+
+func TestPrefixSet(t *testing.T) {
+	a := NewPrefixSet(parsePrefixes("10.0.0.0/24", "10.0.1.0/24", "192.168.0.0/16", "2001:db8::/32")...)
+	b := NewPrefixSet(parsePrefixes("10.0.1.0/24", "10.0.2.0/23", "192.168.10.0/24", "2001:db8:1::/48")...)
+
+	// normalized
+	if got := a.Prefixes(); reflect.DeepEqual(got, parsePrefixes("10.0.0.0/23", "192.168.0.0/16", "2001:db8::/32")) == false {
+		t.Fatalf("unexpected prefixes %v", got)
+	}
+
+	tests := []struct {
+		name     string
+		set      *PrefixSet
+		expected []netip.Prefix
+	}{
+		{
+			"union",
+			a.Union(b),
+			parsePrefixes("10.0.0.0/22", "192.168.0.0/16", "2001:db8::/32"),
+		},
+		{
+			"intersect",
+			a.Intersect(b),
+			parsePrefixes("10.0.1.0/24", "192.168.10.0/24", "2001:db8:1::/48"),
+		},
+		{
+			"difference",
+			a.Difference(b),
+			parsePrefixes(
+				"10.0.0.0/24",
+				"192.168.0.0/21", "192.168.8.0/23", "192.168.11.0/24", "192.168.12.0/22", "192.168.16.0/20",
+				"192.168.32.0/19", "192.168.64.0/18", "192.168.128.0/17",
+				"2001:db8::/48", "2001:db8:2::/47", "2001:db8:4::/46", "2001:db8:8::/45", "2001:db8:10::/44",
+				"2001:db8:20::/43", "2001:db8:40::/42", "2001:db8:80::/41", "2001:db8:100::/40", "2001:db8:200::/39",
+				"2001:db8:400::/38", "2001:db8:800::/37", "2001:db8:1000::/36", "2001:db8:2000::/35",
+				"2001:db8:4000::/34", "2001:db8:8000::/33",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.set.Prefixes(); reflect.DeepEqual(got, tt.expected) == false {
+			t.Fatalf("%v: expected=%v, got=%v", tt.name, tt.expected, got)
+		}
+	}
+
+	if !a.Contains(netip.MustParseAddr("10.0.1.1")) || a.Contains(netip.MustParseAddr("10.0.2.1")) {
+		t.Fatalf("unexpected Contains() result")
+	}
+}
+
+func TestPrefixSetRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	randomSet := func() *PrefixSet {
+		prefixes := []netip.Prefix{}
+		for i := 0; i < 100; i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), 0})
+			prefixes = append(prefixes, netip.PrefixFrom(addr, 14+rnd.Intn(11)))
+		}
+		return NewPrefixSet(prefixes...)
+	}
+
+	for round := 0; round < 20; round++ {
+		a := randomSet()
+		b := randomSet()
+		union := a.Union(b)
+		intersect := a.Intersect(b)
+		difference := a.Difference(b)
+
+		// compare with the membership of each address
+		for i := 0; i < 5000; i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), byte(rnd.Intn(256))})
+			inA := a.Contains(addr)
+			inB := b.Contains(addr)
+			if union.Contains(addr) != (inA || inB) {
+				t.Fatalf("union: %v", addr)
+			}
+			if intersect.Contains(addr) != (inA && inB) {
+				t.Fatalf("intersect: %v", addr)
+			}
+			if difference.Contains(addr) != (inA && !inB) {
+				t.Fatalf("difference: %v", addr)
+			}
+		}
+
+		// the result is normalized, so the same addresses make the same prefixes
+		if reflect.DeepEqual(difference.Union(intersect).Prefixes(), a.Prefixes()) == false {
+			t.Fatalf("(A \\ B) U (A n B) != A")
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package radix
+
+import (
+	"net/netip"
+	"sort"
+)
+
+//
+// PrefixTable stores values keyed by netip.Prefix, for both IPv4 and IPv6.
+// It is backed by two bit oriented radix trees, one for each address family,
+// so the prefixes do not have to be converted to "0101..." strings.
+//
+//   t := NewPrefixTable[string]()
+//   t.Insert(netip.MustParsePrefix("10.0.0.0/8"), "gig1")
+//   t.Insert(netip.MustParsePrefix("2001:db8::/32"), "gig2")
+//   p, v, ok := t.Lookup(netip.MustParseAddr("10.0.0.1")) // 10.0.0.0/8, "gig1", true
+//
+
+// PrefixTable definition
+type PrefixTable[V any] struct {
+	v4 *BitTree[V]
+	v6 *BitTree[V]
+}
+
+// PrefixEntry definition, a prefix and its value
+type PrefixEntry[V any] struct {
+	Prefix netip.Prefix
+	Value  V
+}
+
+// Callback function to pass when exploring the PrefixTable.
+// If true is returned, the search will stop at that point.
+type PrefixWalkCallback[V any] func(p netip.Prefix, v V) bool
+
+// Constructor
+// NewPrefixTable() returns empty PrefixTable instance which stores values of type V
+func NewPrefixTable[V any]() *PrefixTable[V] {
+	return &PrefixTable[V]{
+		v4: NewBitTree[V](),
+		v6: NewBitTree[V](),
+	}
+}
+
+// Len() returns number of prefixes stored in the PrefixTable
+func (t *PrefixTable[V]) Len() int {
+	return t.v4.Len() + t.v6.Len()
+}
+
+// returns the tree for the address family of addr
+func (t *PrefixTable[V]) treeOf(addr netip.Addr) *BitTree[V] {
+	if addr.Is4() {
+		return t.v4
+	}
+	return t.v6
+}
+
+// returns the address as a byte slice, 4 bytes for IPv4 and 16 bytes for IPv6.
+// The slice refers to buf, so that the caller can keep it on the stack.
+func addrBytes(buf *[16]byte, addr netip.Addr) []byte {
+	if addr.Is4() {
+		a := addr.As4()
+		copy(buf[:], a[:])
+		return buf[:4]
+	}
+	*buf = addr.As16()
+	return buf[:]
+}
+
+// converts the key of the bit oriented tree to netip.Prefix
+func bitsToPrefix(key []byte, bitLen int, is4 bool) netip.Prefix {
+	if is4 {
+		var a [4]byte
+		copy(a[:], key)
+		return netip.PrefixFrom(netip.AddrFrom4(a), bitLen)
+	}
+	var a [16]byte
+	copy(a[:], key)
+	return netip.PrefixFrom(netip.AddrFrom16(a), bitLen)
+}
+
+// Add a new prefix and its value to the table.
+// The host bits of the prefix are ignored, 10.0.0.1/8 is same as 10.0.0.0/8.
+// returns true if newly inserted.
+// returns false if update existing prefix, or the prefix is invalid.
+func (t *PrefixTable[V]) Insert(p netip.Prefix, v V) bool {
+	if !p.IsValid() {
+		return false
+	}
+	var buf [16]byte
+	return t.treeOf(p.Addr()).Insert(addrBytes(&buf, p.Addr()), p.Bits(), v)
+}
+
+// If there is a value for the prefix, it will be returned,
+// otherwise zero value and false will be returned.
+func (t *PrefixTable[V]) Get(p netip.Prefix) (V, bool) {
+	if !p.IsValid() {
+		var zero V
+		return zero, false
+	}
+	var buf [16]byte
+	return t.treeOf(p.Addr()).Get(addrBytes(&buf, p.Addr()), p.Bits())
+}
+
+// Delete the prefix and returns its value and true.
+// If the prefix not found, returns zero value and false.
+func (t *PrefixTable[V]) Delete(p netip.Prefix) (V, bool) {
+	if !p.IsValid() {
+		var zero V
+		return zero, false
+	}
+	var buf [16]byte
+	return t.treeOf(p.Addr()).Delete(addrBytes(&buf, p.Addr()), p.Bits())
+}
+
+// Lookup() returns the longest prefix which contains the address and its value.
+// IPv4-mapped IPv6 address is treated as IPv6 address, same as netip.
+func (t *PrefixTable[V]) Lookup(addr netip.Addr) (netip.Prefix, V, bool) {
+	if !addr.IsValid() {
+		var zero V
+		return netip.Prefix{}, zero, false
+	}
+
+	var buf [16]byte
+	n := t.treeOf(addr).longestMatch(addrBytes(&buf, addr), addr.BitLen())
+	if n == nil {
+		var zero V
+		return netip.Prefix{}, zero, false
+	}
+	return bitsToPrefix(n.key, n.bits, addr.Is4()), n.value, true
+}
+
+// Walk() calls the callback function for each prefix, IPv4 first, then IPv6.
+// In each address family, the prefixes are sorted by address, and the shorter prefix comes first.
+func (t *PrefixTable[V]) Walk(fn PrefixWalkCallback[V]) {
+	stopped := false
+	t.v4.Walk(func(key []byte, bitLen int, v V) bool {
+		stopped = fn(bitsToPrefix(key, bitLen, true), v)
+		return stopped
+	})
+	if stopped {
+		return
+	}
+	t.v6.Walk(func(key []byte, bitLen int, v V) bool {
+		return fn(bitsToPrefix(key, bitLen, false), v)
+	})
+}
+
+// Covering() returns the stored prefixes which contain the given prefix (supernets),
+// including the prefix itself, from the shortest to the longest.
+func (t *PrefixTable[V]) Covering(p netip.Prefix) []PrefixEntry[V] {
+	entries := []PrefixEntry[V]{}
+	if !p.IsValid() {
+		return entries
+	}
+
+	var buf [16]byte
+	is4 := p.Addr().Is4()
+	t.treeOf(p.Addr()).WalkPath(addrBytes(&buf, p.Addr()), p.Bits(), func(key []byte, bitLen int, v V) bool {
+		entries = append(entries, PrefixEntry[V]{Prefix: bitsToPrefix(key, bitLen, is4), Value: v})
+		return false
+	})
+	return entries
+}
+
+// CoveredBy() returns the stored prefixes which are contained in the given prefix (subnets),
+// including the prefix itself, from the shortest to the longest.
+// The prefixes of the same length are sorted by address.
+func (t *PrefixTable[V]) CoveredBy(p netip.Prefix) []PrefixEntry[V] {
+	entries := []PrefixEntry[V]{}
+	if !p.IsValid() {
+		return entries
+	}
+
+	var buf [16]byte
+	is4 := p.Addr().Is4()
+	t.treeOf(p.Addr()).WalkPrefix(addrBytes(&buf, p.Addr()), p.Bits(), func(key []byte, bitLen int, v V) bool {
+		entries = append(entries, PrefixEntry[V]{Prefix: bitsToPrefix(key, bitLen, is4), Value: v})
+		return false
+	})
+
+	// the walk is sorted by address, keep it for the same length
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Prefix.Bits() < entries[j].Prefix.Bits()
+	})
+	return entries
+}
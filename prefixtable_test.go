@@ -0,0 +1,257 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixTable(t *testing.T) {
+	// routing table
+	routes := []struct {
+		prefix  string
+		gateway string
+	}{
+		{"0.0.0.0/0", "default"},
+		{"10.0.0.0/8", "gig1"},
+		{"10.0.0.0/16", "gig2"},
+		{"10.0.0.0/24", "gig3"},
+		{"192.168.0.0/24", "gig4"},
+		{"192.168.0.1/32", "gig6"},
+		{"192.168.0.128/25", "gig5"},
+		{"2001:db8::/32", "gig7"},
+		{"2001:db8:1::/48", "gig8"},
+		{"2001:db8:1:1::/64", "gig9"},
+	}
+
+	r := NewPrefixTable[string]()
+
+	for _, route := range routes {
+		if !r.Insert(netip.MustParsePrefix(route.prefix), route.gateway) {
+			t.Fatalf("insert failed %v", route.prefix)
+		}
+	}
+
+	if r.Len() != len(routes) {
+		t.Fatalf("expected length=%v, got=%v", len(routes), r.Len())
+	}
+
+	tests := []struct {
+		destination string
+		prefix      string
+		expected    string
+	}{
+		{"10.0.0.1", "10.0.0.0/24", "gig3"},
+		{"10.0.1.1", "10.0.0.0/16", "gig2"},
+		{"10.1.1.1", "10.0.0.0/8", "gig1"},
+		{"192.168.0.1", "192.168.0.1/32", "gig6"},
+		{"192.168.0.2", "192.168.0.0/24", "gig4"},
+		{"192.168.0.129", "192.168.0.128/25", "gig5"},
+		{"172.16.0.1", "0.0.0.0/0", "default"},
+		{"2001:db8::1", "2001:db8::/32", "gig7"},
+		{"2001:db8:1::1", "2001:db8:1::/48", "gig8"},
+		{"2001:db8:1:1::1", "2001:db8:1:1::/64", "gig9"},
+	}
+
+	for _, test := range tests {
+		p, v, found := r.Lookup(netip.MustParseAddr(test.destination))
+		if found == false {
+			t.Fatalf("key not found: %v", test.destination)
+		}
+		if test.expected != v || test.prefix != p.String() {
+			t.Fatalf("expected: %v %v, got: %v %v", test.prefix, test.expected, p, v)
+		}
+	}
+
+	// no IPv6 default route
+	if _, _, found := r.Lookup(netip.MustParseAddr("2001:db9::1")); found {
+		t.Fatalf("unexpected match: %v", "2001:db9::1")
+	}
+
+	// exact match ignores the host bits
+	if v, ok := r.Get(netip.MustParsePrefix("10.1.2.3/8")); !ok || v != "gig1" {
+		t.Fatalf("expected: %v, got: %v", "gig1", v)
+	}
+	if _, ok := r.Get(netip.MustParsePrefix("10.0.0.0/12")); ok {
+		t.Fatalf("unexpected match: %v", "10.0.0.0/12")
+	}
+
+	// walk in order
+	got := []string{}
+	r.Walk(func(p netip.Prefix, v string) bool {
+		got = append(got, p.String())
+		return false
+	})
+	expected := []string{}
+	for _, route := range routes {
+		expected = append(expected, route.prefix)
+	}
+	if reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+
+	// delete
+	for _, route := range routes {
+		v, ok := r.Delete(netip.MustParsePrefix(route.prefix))
+		if !ok || v != route.gateway {
+			t.Fatalf("delete failed %v", route.prefix)
+		}
+		if _, ok := r.Get(netip.MustParsePrefix(route.prefix)); ok {
+			t.Fatalf("found after delete %v", route.prefix)
+		}
+	}
+
+	if r.Len() != 0 {
+		t.Fatalf("expected length=%v, got=%v", 0, r.Len())
+	}
+
+	// invalid prefix
+	if r.Insert(netip.Prefix{}, "invalid") {
+		t.Fatalf("invalid prefix inserted")
+	}
+}
+
+func TestPrefixTableSameAsTree(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	r1 := NewTree[int]()
+	r2 := NewPrefixTable[int]()
+
+	randomPrefix := func() netip.Prefix {
+		addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), 0})
+		return netip.PrefixFrom(addr, 8+rnd.Intn(17)).Masked()
+	}
+
+	// apply the same random operations to the string tree and the prefix table
+	for i := 0; i < 5000; i++ {
+		p := randomPrefix()
+		addr, masklen, _ := cidrToBinaryString(p.String())
+		if rnd.Intn(3) == 0 {
+			_, ok1 := r1.Delete(addr[:masklen])
+			_, ok2 := r2.Delete(p)
+			if ok1 != ok2 {
+				t.Fatalf("delete %v: expected=%v, got=%v", p, ok1, ok2)
+			}
+		} else {
+			ok1 := r1.Insert(addr[:masklen], i)
+			ok2 := r2.Insert(p, i)
+			if ok1 != ok2 {
+				t.Fatalf("insert %v: expected=%v, got=%v", p, ok1, ok2)
+			}
+		}
+	}
+
+	if r1.Len() != r2.Len() {
+		t.Fatalf("expected length=%v, got=%v", r1.Len(), r2.Len())
+	}
+
+	for i := 0; i < 1000; i++ {
+		addr := netip.AddrFrom4([4]byte{10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), byte(rnd.Intn(256))})
+		key, _ := addrToBinaryString(addr.String())
+
+		k, v1, ok1 := r1.LongestMatch(key)
+		p, v2, ok2 := r2.Lookup(addr)
+		if ok1 != ok2 || v1 != v2 {
+			t.Fatalf("lookup %v: expected=%v %v, got=%v %v", addr, k, v1, p, v2)
+		}
+		if ok2 && p.Bits() != len(k) {
+			t.Fatalf("lookup %v: expected=%v, got=%v", addr, len(k), p.Bits())
+		}
+	}
+}
+
+func BenchmarkPrefixTableLookup(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+
+	r := NewPrefixTable[int]()
+	for i := 0; i < 10000; i++ {
+		addr := netip.AddrFrom4([4]byte{byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256)), 0})
+		r.Insert(netip.PrefixFrom(addr, 8+rnd.Intn(17)), i)
+	}
+
+	addrs := make([]netip.Addr, 1000)
+	for i := range addrs {
+		addrs[i] = netip.MustParseAddr(fmt.Sprintf("%d.%d.%d.%d", rnd.Intn(256), rnd.Intn(256), rnd.Intn(256), rnd.Intn(256)))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Lookup(addrs[i%len(addrs)])
+	}
+}
+
+func TestPrefixTableCovering(t *testing.T) {
+	prefixes := []string{
+		"0.0.0.0/0",
+		"10.0.0.0/8",
+		"10.0.0.0/16",
+		"10.0.0.0/24",
+		"10.0.1.0/24",
+		"10.1.0.0/16",
+		"10.0.0.128/25",
+		"192.168.0.0/24",
+		"2001:db8::/32",
+		"2001:db8:1::/48",
+	}
+
+	r := NewPrefixTable[int]()
+	for i, p := range prefixes {
+		r.Insert(netip.MustParsePrefix(p), i)
+	}
+
+	toStrings := func(entries []PrefixEntry[int]) []string {
+		ss := []string{}
+		for _, e := range entries {
+			if prefixes[e.Value] != e.Prefix.String() {
+				t.Fatalf("unexpected value %v for %v", e.Value, e.Prefix)
+			}
+			ss = append(ss, e.Prefix.String())
+		}
+		return ss
+	}
+
+	tests := []struct {
+		prefix    string
+		covering  []string
+		coveredBy []string
+	}{
+		{
+			"10.0.0.0/16",
+			[]string{"0.0.0.0/0", "10.0.0.0/8", "10.0.0.0/16"},
+			[]string{"10.0.0.0/16", "10.0.0.0/24", "10.0.1.0/24", "10.0.0.128/25"},
+		},
+		{
+			"10.0.0.0/12",
+			[]string{"0.0.0.0/0", "10.0.0.0/8"},
+			[]string{"10.0.0.0/16", "10.1.0.0/16", "10.0.0.0/24", "10.0.1.0/24", "10.0.0.128/25"},
+		},
+		{
+			"10.0.0.200/32",
+			[]string{"0.0.0.0/0", "10.0.0.0/8", "10.0.0.0/16", "10.0.0.0/24", "10.0.0.128/25"},
+			[]string{},
+		},
+		{
+			"172.16.0.0/12",
+			[]string{"0.0.0.0/0"},
+			[]string{},
+		},
+		{
+			"2001:db8::/16",
+			[]string{},
+			[]string{"2001:db8::/32", "2001:db8:1::/48"},
+		},
+	}
+
+	for _, tt := range tests {
+		p := netip.MustParsePrefix(tt.prefix)
+		if got := toStrings(r.Covering(p)); reflect.DeepEqual(got, tt.covering) == false {
+			t.Fatalf("Covering(%v): expected=%v, got=%v", p, tt.covering, got)
+		}
+		if got := toStrings(r.CoveredBy(p)); reflect.DeepEqual(got, tt.coveredBy) == false {
+			t.Fatalf("CoveredBy(%v): expected=%v, got=%v", p, tt.coveredBy, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package radix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//
+// PublicSuffixList finds the public suffix and the registrable domain of the host name
+// by the rules of the Public Suffix List (https://publicsuffix.org/list/).
+// The list is loaded from a local file, it is never fetched from the network.
+//
+// The rules are stored in the tree with the reversed labels same as DomainTree,
+// and WalkPath() visits all the rules which match the host name from the top level domain.
+//
+//   jp                    normal rule
+//   *.kawasaki.jp         wildcard rule, any label under kawasaki.jp is a public suffix
+//   !city.kawasaki.jp     exception rule, city.kawasaki.jp is not a public suffix
+//
+//   PublicSuffix("www.example.kawasaki.jp")       => "example.kawasaki.jp"
+//   RegistrableDomain("www.example.kawasaki.jp")  => "www.example.kawasaki.jp"
+//   RegistrableDomain("www.city.kawasaki.jp")     => "city.kawasaki.jp"
+//
+// The exception rule wins, otherwise the rule with the most labels wins.
+// If no rule matches, the top level domain is the public suffix ("*" rule).
+//
+
+// kinds of the rules
+const (
+	pslNormal uint8 = 1 << iota
+	pslWildcard
+	pslException
+)
+
+// entry of PublicSuffixList, the rules of the same name
+type pslEntry struct {
+	rules   uint8 // pslNormal, pslWildcard and pslException
+	private uint8 // the rules in the private section
+}
+
+// PublicSuffixList definition
+type PublicSuffixList struct {
+	tree *TypedTree[pslEntry]
+	size int
+}
+
+// Constructor
+// NewPublicSuffixList() returns empty PublicSuffixList, which has only the default "*" rule
+func NewPublicSuffixList() *PublicSuffixList {
+	return &PublicSuffixList{
+		tree: NewTree[pslEntry](),
+		size: 0,
+	}
+}
+
+// LoadPublicSuffixList() reads the file in the format of public_suffix_list.dat.
+// The rules between "===BEGIN PRIVATE DOMAINS===" and "===END PRIVATE DOMAINS===" are the private rules,
+// the others are the ICANN rules.
+func LoadPublicSuffixList(r io.Reader) (*PublicSuffixList, error) {
+	l := NewPublicSuffixList()
+	private := false
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if comment, ok := strings.CutPrefix(line, "//"); ok {
+			switch strings.TrimSpace(comment) {
+			case "===BEGIN PRIVATE DOMAINS===":
+				private = true
+			case "===END PRIVATE DOMAINS===":
+				private = false
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		// the rule is the first word of the line
+		rule := strings.Fields(line)[0]
+		if err := l.Add(rule, !private); err != nil {
+			return nil, fmt.Errorf("radix: line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Len() returns number of the rules
+func (l *PublicSuffixList) Len() int {
+	return l.size
+}
+
+// Add() adds the rule such as "jp", "*.kawasaki.jp" or "!city.kawasaki.jp".
+// icann is false for the rules in the private section.
+func (l *PublicSuffixList) Add(rule string, icann bool) error {
+	kind := pslNormal
+	if rest, ok := strings.CutPrefix(rule, "!"); ok {
+		kind, rule = pslException, rest
+	} else if rest, ok := strings.CutPrefix(rule, "*."); ok {
+		kind, rule = pslWildcard, rest
+	}
+
+	name, err := NormalizeDomain(rule)
+	if err != nil {
+		return err
+	}
+	// the exception rule must be under a public suffix
+	if kind == pslException && !strings.Contains(name, ".") {
+		return fmt.Errorf("radix: invalid exception rule %q", rule)
+	}
+
+	key := domainKey(name)
+	e, _ := l.tree.Get(key)
+	if e.rules&kind == 0 {
+		l.size++
+	}
+	e.rules |= kind
+	if icann {
+		e.private &^= kind
+	} else {
+		e.private |= kind
+	}
+	l.tree.Insert(key, e)
+	return nil
+}
+
+// PublicSuffix() returns the public suffix of the host name,
+// and true if the suffix is managed by ICANN, false if it is in the private section or no rule matches.
+// If the host name is invalid, returns empty string and false.
+func (l *PublicSuffixList) PublicSuffix(host string) (string, bool) {
+	name, err := NormalizeDomain(host)
+	if err != nil {
+		return "", false
+	}
+	labels := strings.Split(name, ".")
+	n, icann := l.suffixLabels(domainKey(name), len(labels))
+	return strings.Join(labels[len(labels)-n:], "."), icann
+}
+
+// returns the number of labels of the public suffix, and true if the prevailing rule is ICANN rule
+func (l *PublicSuffixList) suffixLabels(key string, labels int) (int, bool) {
+	// default rule "*"
+	n, icann := 1, false
+
+	l.tree.WalkPath(key, func(k string, e pslEntry) bool {
+		depth := strings.Count(k, ".")
+		if e.rules&pslException != 0 {
+			n, icann = depth-1, e.private&pslException == 0
+			return true
+		}
+		if e.rules&pslNormal != 0 && depth >= n {
+			n, icann = depth, e.private&pslNormal == 0
+		}
+		// the wildcard needs one more label
+		if e.rules&pslWildcard != 0 && depth < labels && depth+1 >= n {
+			n, icann = depth+1, e.private&pslWildcard == 0
+		}
+		return false
+	})
+	return n, icann
+}
+
+// RegistrableDomain() returns the public suffix and one more label, such as "example.co.uk" of "www.example.co.uk".
+// returns error if the host name is invalid or it is a public suffix itself.
+func (l *PublicSuffixList) RegistrableDomain(host string) (string, error) {
+	name, err := NormalizeDomain(host)
+	if err != nil {
+		return "", err
+	}
+	labels := strings.Split(name, ".")
+	n, _ := l.suffixLabels(domainKey(name), len(labels))
+	if n >= len(labels) {
+		return "", fmt.Errorf("radix: %q is a public suffix", name)
+	}
+	return strings.Join(labels[len(labels)-n-1:], "."), nil
+}
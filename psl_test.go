@@ -0,0 +1,136 @@
+package radix
+
+import (
+	"strings"
+	"testing"
+)
+
+// excerpt of public_suffix_list.dat
+const publicSuffixList = `// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0.
+
+// ===BEGIN ICANN DOMAINS===
+
+// ck : https://en.wikipedia.org/wiki/.ck
+*.ck
+!www.ck
+
+// cn : https://en.wikipedia.org/wiki/.cn
+cn
+com.cn
+公司.cn
+
+com
+uk
+co.uk
+
+// jp : https://en.wikipedia.org/wiki/.jp
+jp
+ac.jp
+kyoto.jp
+ide.kyoto.jp
+*.kobe.jp
+!city.kobe.jp
+
+// ===END ICANN DOMAINS===
+// ===BEGIN PRIVATE DOMAINS===
+
+// Google, Inc.
+blogspot.com
+blogspot.co.uk
+
+github.io
+*.compute.amazonaws.com
+
+// ===END PRIVATE DOMAINS===
+`
+
+func TestPublicSuffixList(t *testing.T) {
+	l, err := LoadPublicSuffixList(strings.NewReader(publicSuffixList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 18 {
+		t.Fatalf("expected=18, got=%v", l.Len())
+	}
+
+	// from test_psl.txt, empty registrable domain is the public suffix itself
+	tests := []struct {
+		host        string
+		suffix      string
+		icann       bool
+		registrable string
+	}{
+		{"com", "com", true, ""},
+		{"example.COM", "com", true, "example.com"},
+		{"a.b.example.com.", "com", true, "example.com"},
+		{"example", "example", false, ""},
+		{"example.example", "example", false, "example.example"},
+		{"b.example.example", "example", false, "example.example"},
+		{"co.uk", "co.uk", true, ""},
+		{"www.example.co.uk", "co.uk", true, "example.co.uk"},
+		{"jp", "jp", true, ""},
+		{"test.jp", "jp", true, "test.jp"},
+		{"www.test.jp", "jp", true, "test.jp"},
+		{"ac.jp", "ac.jp", true, ""},
+		{"test.ac.jp", "ac.jp", true, "test.ac.jp"},
+		{"kyoto.jp", "kyoto.jp", true, ""},
+		{"test.kyoto.jp", "kyoto.jp", true, "test.kyoto.jp"},
+		{"ide.kyoto.jp", "ide.kyoto.jp", true, ""},
+		{"b.ide.kyoto.jp", "ide.kyoto.jp", true, "b.ide.kyoto.jp"},
+		{"c.kobe.jp", "c.kobe.jp", true, ""},
+		{"b.c.kobe.jp", "c.kobe.jp", true, "b.c.kobe.jp"},
+		{"a.b.c.kobe.jp", "c.kobe.jp", true, "b.c.kobe.jp"},
+		{"city.kobe.jp", "kobe.jp", true, "city.kobe.jp"},
+		{"www.city.kobe.jp", "kobe.jp", true, "city.kobe.jp"},
+		{"ck", "ck", false, ""},
+		{"test.ck", "test.ck", true, ""},
+		{"b.test.ck", "test.ck", true, "b.test.ck"},
+		{"www.ck", "ck", true, "www.ck"},
+		{"www.www.ck", "ck", true, "www.ck"},
+		{"食狮.com.cn", "com.cn", true, "xn--85x722f.com.cn"},
+		{"食狮.公司.cn", "xn--55qx5d.cn", true, "xn--85x722f.xn--55qx5d.cn"},
+		{"www.食狮.公司.cn", "xn--55qx5d.cn", true, "xn--85x722f.xn--55qx5d.cn"},
+		{"公司.cn", "xn--55qx5d.cn", true, ""},
+		{"foo.blogspot.com", "blogspot.com", false, "foo.blogspot.com"},
+		{"blogspot.com", "blogspot.com", false, ""},
+		{"foo.github.io", "github.io", false, "foo.github.io"},
+		{"a.b.compute.amazonaws.com", "b.compute.amazonaws.com", false, "a.b.compute.amazonaws.com"},
+		{"compute.amazonaws.com", "com", true, "amazonaws.com"},
+	}
+	for _, tt := range tests {
+		suffix, icann := l.PublicSuffix(tt.host)
+		if suffix != tt.suffix || icann != tt.icann {
+			t.Fatalf("host=%v, expected=%v %v, got=%v %v", tt.host, tt.suffix, tt.icann, suffix, icann)
+		}
+
+		registrable, err := l.RegistrableDomain(tt.host)
+		if tt.registrable == "" {
+			if err == nil {
+				t.Fatalf("host=%v, expected error, got=%v", tt.host, registrable)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registrable != tt.registrable {
+			t.Fatalf("host=%v, expected=%v, got=%v", tt.host, tt.registrable, registrable)
+		}
+	}
+
+	// invalid host name
+	if suffix, icann := l.PublicSuffix("a..com"); suffix != "" || icann {
+		t.Fatalf("expected empty suffix, got=%v %v", suffix, icann)
+	}
+	if _, err := l.RegistrableDomain(""); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// invalid rules
+	for _, list := range []string{"!jp\n", "com\nexa/mple\n", "a..b\n"} {
+		if _, err := LoadPublicSuffixList(strings.NewReader(list)); err == nil {
+			t.Fatalf("expected error, list=%q", list)
+		}
+	}
+}
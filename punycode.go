@@ -0,0 +1,120 @@
+package radix
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+//
+// Punycode (RFC 3492) encoder for the internationalized domain labels.
+//
+//   "bücher" => "bcher-kva"      (xn--bcher-kva)
+//   "日本語"  => "wgv71a119e"     (xn--wgv71a119e)
+//
+
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunycodeOverflow = errors.New("radix: punycode overflow")
+
+// returns the character of the digit, a-z for 0-25 and 0-9 for 26-35
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+// bias adaptation function, section 6.1 of RFC 3492
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+// punycodeEncode() returns the punycode of the label, without "xn--" prefix
+func punycodeEncode(label string) (string, error) {
+	runes := []rune(label)
+
+	var b strings.Builder
+	for _, r := range runes {
+		if r < 0x80 {
+			b.WriteByte(byte(r))
+		}
+	}
+	basic := b.Len()
+	if basic > 0 {
+		b.WriteByte('-')
+	}
+
+	n := punyInitialN
+	delta := 0
+	bias := punyInitialBias
+	for h := basic; h < len(runes); {
+		// the smallest code point which is not handled yet
+		m := math.MaxInt32
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if m-n > (math.MaxInt32-delta)/(h+1) {
+			return "", errPunycodeOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+				if delta == math.MaxInt32 {
+					return "", errPunycodeOverflow
+				}
+			}
+			if int(r) != n {
+				continue
+			}
+
+			// write delta as a variable length integer
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+				if q < t {
+					break
+				}
+				b.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			b.WriteByte(punyDigit(q))
+
+			bias = punyAdapt(delta, h+1, h == basic)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return b.String(), nil
+}
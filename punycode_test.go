@@ -0,0 +1,34 @@
+package radix
+
+import (
+	"testing"
+)
+
+func TestPunycodeEncode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"bücher", "bcher-kva"},
+		{"münchen", "mnchen-3ya"},
+		{"日本語", "wgv71a119e"},
+		{"例え", "r8jz45g"},
+		{"ドメイン名例", "eckwd4c7cu47r2wf"},
+		{"abc", "abc-"},
+		// samples in section 7.1 of RFC 3492
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+		{"他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+		{"Pročprostěnemluvíčesky", "Proprostnemluvesky-uyb24dma41a"},
+		{"3年B組金八先生", "3B-ww4c5e180e575a65lsy2b"},
+	}
+
+	for _, tt := range tests {
+		got, err := punycodeEncode(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.expected {
+			t.Fatalf("input=%v, expected=%v, got=%v", tt.input, tt.expected, got)
+		}
+	}
+}
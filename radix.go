@@ -4,28 +4,44 @@ import (
 	"sort"
 )
 
-// Tree definition
-type Tree struct {
-	root *node
+// TypedTree definition
+// V is the type of the values stored in the Tree
+type TypedTree[V any] struct {
+	root *node[V]
 	size int
 }
 
+// Tree is the untyped Tree which stores interface{} values, same as the former API
+type Tree = TypedTree[any]
+
+// Leaf is the key-value-pair of the untyped Tree
+type Leaf = TypedLeaf[any]
+
+// WalkCallback is the callback function of the untyped Tree
+type WalkCallback = TypedWalkCallback[any]
+
 // Constructor
-// New() returns empty Tree instance
+// New() returns empty untyped Tree instance which stores interface{} values
 func New() *Tree {
-	return &Tree{
-		root: &node{},
+	return NewTree[any]()
+}
+
+// Constructor
+// NewTree() returns empty Tree instance which stores values of type V
+func NewTree[V any]() *TypedTree[V] {
+	return &TypedTree[V]{
+		root: &node[V]{},
 		size: 0,
 	}
 }
 
 // Len() returns number of key-value-pairs stored in the Tree
-func (t *Tree) Len() int {
+func (t *TypedTree[V]) Len() int {
 	return t.size
 }
 
 // Load() receive a mpa and store its contents in the Tree
-func (t *Tree) Load(m map[string]interface{}) {
+func (t *TypedTree[V]) Load(m map[string]V) {
 	for k, v := range m {
 		t.Insert(k, v)
 	}
@@ -33,9 +49,9 @@ func (t *Tree) Load(m map[string]interface{}) {
 
 // ToMap() is reverse of Load()
 // Convert key-value-pair to map and return
-func (t *Tree) ToMap() map[string]interface{} {
-	m := make(map[string]interface{}, t.size)
-	t.Walk(func(k string, v interface{}) bool {
+func (t *TypedTree[V]) ToMap() map[string]V {
+	m := make(map[string]V, t.size)
+	t.Walk(func(k string, v V) bool {
 		m[k] = v
 		return false
 	})
@@ -43,48 +59,64 @@ func (t *Tree) ToMap() map[string]interface{} {
 }
 
 // node definition
-type node struct {
-	leaf     *Leaf  // reference to a leaf node or nil
-	prefixes []rune // Unique part excluding the intersection until this node
-	edges    []edge // slice of edge, always kept sorted
+type node[V any] struct {
+	leaf     *TypedLeaf[V] // reference to a leaf node or nil
+	prefixes []rune        // Unique part excluding the intersection until this node
+	edges    []edge[V]     // slice of edge, always kept sorted
 }
 
-// Leaf definition, Leaf stores a key-value-pair
-type Leaf struct {
+// TypedLeaf definition, TypedLeaf stores a key-value-pair
+type TypedLeaf[V any] struct {
 	key   string
-	value interface{}
+	value V
+}
+
+// Key() returns the key of the Leaf
+func (l TypedLeaf[V]) Key() string {
+	return l.key
+}
+
+// Value() returns the value of the Leaf
+func (l TypedLeaf[V]) Value() V {
+	return l.value
 }
 
 // edge definition, edge have single-letter labels that identify branches
-type edge struct {
-	label rune  // single letter
-	node  *node // child node
+type edge[V any] struct {
+	label rune     // single letter
+	node  *node[V] // child node
 }
 
 // returns true if it has a reference to a leaf node
-func (n *node) isLeaf() bool {
+func (n *node[V]) isLeaf() bool {
 	return n.leaf != nil
 }
 
 // add the specified edge
-func (n *node) addEdge(e edge) {
+func (n *node[V]) addEdge(e edge[V]) {
 	n.edges = append(n.edges, e)
 	// keep sorted
 	sort.Slice(n.edges, func(i, j int) bool { return n.edges[i].label < n.edges[j].label })
 }
 
 // return number of edges
-func (n *node) edgeLen() int {
+func (n *node[V]) edgeLen() int {
 	return len(n.edges)
 }
 
+// returns the index of the edge with the specified label letter,
+// or the index where the edge should be inserted
+func (n *node[V]) edgeIndex(label rune) int {
+	return sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].label >= label
+	})
+}
+
 // returns the child node beyond the edge of the specified label letter
-func (n *node) getChild(label rune) *node {
+func (n *node[V]) getChild(label rune) *node[V] {
 	// find the same label in the edge slice
 	length := len(n.edges)
-	index := sort.Search(length, func(i int) bool {
-		return n.edges[i].label >= label
-	})
+	index := n.edgeIndex(label)
 
 	// if found, return the child node at the end of the edge
 	if index < length && n.edges[index].label == label {
@@ -96,7 +128,7 @@ func (n *node) getChild(label rune) *node {
 
 // if there is an edge corresponding to the specified label letter,
 // change the reference to the child node and return true, otherwise return false
-func (n *node) updateEdge(label rune, node *node) bool {
+func (n *node[V]) updateEdge(label rune, node *node[V]) bool {
 	// find the same label in the edge slice
 	length := len(n.edges)
 	index := sort.Search(length, func(i int) bool {
@@ -113,7 +145,7 @@ func (n *node) updateEdge(label rune, node *node) bool {
 }
 
 // delete the edge with the specified label
-func (n *node) deleteEdge(label rune) bool {
+func (n *node[V]) deleteEdge(label rune) bool {
 	length := len(n.edges)
 	index := sort.Search(length, func(i int) bool {
 		return n.edges[i].label >= label
@@ -125,7 +157,7 @@ func (n *node) deleteEdge(label rune) bool {
 
 		/*
 			copy(n.edges[index:], n.edges[index+1:])
-			n.edges[len(n.edges)-1] = edge{}   // replace last element as empty edge
+			n.edges[len(n.edges)-1] = edge[V]{}   // replace last element as empty edge
 			n.edges = n.edges[:len(n.edges)-1] // remove last element
 		*/
 
@@ -177,11 +209,11 @@ func startsWith(runes, prefixes []rune) bool {
 // Add a new key-value pair to the tree.
 // returns true if newly inserted.
 // returns false if update existing key-value pair.
-func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
+func (t *TypedTree[V]) Insert(k string, v V) (inserted bool) {
 	// Make a rune slice of k and use it as a search key
 	searches := []rune(k)
 
-	var parent *node
+	var parent *node[V]
 	n := t.root
 	for {
 
@@ -194,7 +226,7 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 			}
 
 			// create a new leaf
-			n.leaf = &Leaf{
+			n.leaf = &TypedLeaf[V]{
 				key:   k,
 				value: v,
 			}
@@ -210,10 +242,10 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 
 		// if child node n does not exist, create an edge, spawn a new branch and exit
 		if n == nil {
-			e := edge{
+			e := edge[V]{
 				label: searches[0],
-				node: &node{
-					leaf: &Leaf{
+				node: &node[V]{
+					leaf: &TypedLeaf[V]{
 						key:   k,
 						value: v,
 					},
@@ -247,7 +279,7 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 		//                               +-(edge)--- newNode
 		//
 
-		n1 := &node{}                        // create new node n1
+		n1 := &node[V]{}                     // create new node n1
 		n1.prefixes = n.prefixes[:commonLen] // n1 has common part of the prefixes
 
 		n2 := n                              // n2 should take over n
@@ -255,10 +287,10 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 
 		parent.updateEdge(searches[0], n1) // change the parent node's edge from n to n1
 
-		n1.addEdge(edge{label: n2.prefixes[0], node: n2}) // add edge to n2
+		n1.addEdge(edge[V]{label: n2.prefixes[0], node: n2}) // add edge to n2
 
 		// create new leaf and size +1
-		leaf := &Leaf{
+		leaf := &TypedLeaf[V]{
 			key:   k,
 			value: v,
 		}
@@ -272,9 +304,9 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 			n1.leaf = leaf
 		} else {
 			// add new edge to n1 and hang a new node with leaf
-			n1.addEdge(edge{
+			n1.addEdge(edge[V]{
 				label: prefixes[0],
-				node: &node{
+				node: &node[V]{
 					leaf:     leaf,
 					prefixes: prefixes,
 				},
@@ -286,16 +318,15 @@ func (t *Tree) Insert(k string, v interface{}) (inserted bool) {
 }
 
 // Delete key-value pair and returns its value and true.
-// If key not found, returns nil and false.
-func (t *Tree) Delete(key string) (value interface{}, deleted bool) {
+// If key not found, returns zero value and false.
+func (t *TypedTree[V]) Delete(key string) (value V, deleted bool) {
 	// default (when not deleted) return value
-	value = nil
 	deleted = false
 
 	// Search logic is similar to Insert ()
 
 	searches := []rune(key)
-	var parent *node
+	var parent *node[V]
 	var parentLabel rune
 	n := t.root
 	for {
@@ -344,7 +375,7 @@ func (t *Tree) Delete(key string) (value interface{}, deleted bool) {
 	return value, deleted
 }
 
-func (n *node) mergeChild() {
+func (n *node[V]) mergeChild() {
 	if len(n.edges) != 1 {
 		return
 	}
@@ -356,8 +387,8 @@ func (n *node) mergeChild() {
 }
 
 // If there is a key-value pair corresponding to given key, it will be returned,
-// otherwise nil and false will be returned.
-func (t *Tree) Get(key string) (interface{}, bool) {
+// otherwise zero value and false will be returned.
+func (t *TypedTree[V]) Get(key string) (V, bool) {
 	searches := []rune(key)
 	n := t.root
 	for {
@@ -380,13 +411,14 @@ func (t *Tree) Get(key string) (interface{}, bool) {
 			break
 		}
 	}
-	return nil, false
+	var zero V
+	return zero, false
 }
 
 // Returns the closest key-value pair in a longest match rule
-func (t *Tree) LongestMatch(key string) (string, interface{}, bool) {
+func (t *TypedTree[V]) LongestMatch(key string) (string, V, bool) {
 	searches := []rune(key)
-	var last *Leaf
+	var last *TypedLeaf[V]
 	n := t.root
 	for {
 		if n.isLeaf() {
@@ -415,26 +447,70 @@ func (t *Tree) LongestMatch(key string) (string, interface{}, bool) {
 		return last.key, last.value, true
 	}
 
-	return "", nil, false
+	var zero V
+	return "", zero, false
+}
+
+// Returns the closest key-value pair in a shortest match rule,
+// that is the first leaf which LongestMatch() passes through
+func (t *TypedTree[V]) ShortestMatch(key string) (string, V, bool) {
+	var first *TypedLeaf[V]
+	t.WalkPath(key, func(k string, v V) bool {
+		first = &TypedLeaf[V]{key: k, value: v}
+		return true
+	})
+
+	if first != nil {
+		return first.key, first.value, true
+	}
+
+	var zero V
+	return "", zero, false
+}
+
+// Returns all key-value pairs whose key is a prefix of a given key, from the shortest to the longest.
+// The last one is same as LongestMatch().
+func (t *TypedTree[V]) PrefixMatches(key string) []TypedLeaf[V] {
+	leafs := []TypedLeaf[V]{}
+	t.WalkPath(key, func(k string, v V) bool {
+		leafs = append(leafs, TypedLeaf[V]{key: k, value: v})
+		return false
+	})
+	return leafs
 }
 
 // Find all key-values starting with a given key
-func (t *Tree) Collect(key string) []Leaf {
-	leafs := []Leaf{}
+func (t *TypedTree[V]) Collect(key string) []TypedLeaf[V] {
+	leafs := []TypedLeaf[V]{}
+
+	found := t.prefixNode(key)
+	if found == nil {
+		return leafs
+	}
+
+	// starting from the found, collect all key-value pairs
+	walk(found, func(k string, v V) bool {
+		leafs = append(leafs, TypedLeaf[V]{key: k, value: v})
+		return false
+	})
 
+	return leafs
+}
+
+// returns the deepest node whose all keys below start with a given key,
+// or nil if there is no such key in this tree
+func (t *TypedTree[V]) prefixNode(key string) *node[V] {
 	searches := []rune(key)
-	var found *node
 	n := t.root
 	for {
 		if len(searches) == 0 {
-			found = n
-			break
+			return n
 		}
 
 		n = n.getChild(searches[0])
 		if n == nil {
 			// no child means key not found in this tree
-			return leafs
+			return nil
 		}
 
 		if startsWith(searches, n.prefixes) {
@@ -443,25 +519,13 @@ func (t *Tree) Collect(key string) []Leaf {
 		}
 
 		if startsWith(n.prefixes, searches) {
-			found = n
+			return n
 		}
-		break
+		return nil
 	}
-
-	if found == nil {
-		return leafs
-	}
-
-	// starting from the found, collect all key-value pairs
-	walk(found, func(k string, v interface{}) bool {
-		leafs = append(leafs, Leaf{key: k, value: v})
-		return false
-	})
-
-	return leafs
 }
 
-func (t *Tree) CollectKeys(key string) []string {
+func (t *TypedTree[V]) CollectKeys(key string) []string {
 	leafs := t.Collect(key)
 	if leafs == nil {
 		return []string{}
@@ -474,8 +538,28 @@ func (t *Tree) CollectKeys(key string) []string {
 	return keys
 }
 
+// Find all values whose key starts with a given key, in order of the keys
+func (t *TypedTree[V]) CollectValues(key string) []V {
+	values := []V{}
+	t.WalkPrefix(key, func(k string, v V) bool {
+		values = append(values, v)
+		return false
+	})
+	return values
+}
+
+// Find all key-values starting with a given key and return them as a map
+func (t *TypedTree[V]) CollectMap(key string) map[string]V {
+	m := map[string]V{}
+	t.WalkPrefix(key, func(k string, v V) bool {
+		m[k] = v
+		return false
+	})
+	return m
+}
+
 // The edges are sorted, so if you follow the younger edge, you will reach the top value.
-func (t *Tree) Top() (string, interface{}, bool) {
+func (t *TypedTree[V]) Top() (string, V, bool) {
 	n := t.root
 	for {
 		if n.isLeaf() {
@@ -487,11 +571,12 @@ func (t *Tree) Top() (string, interface{}, bool) {
 			break
 		}
 	}
-	return "", nil, false
+	var zero V
+	return "", zero, false
 }
 
 // The edges are sorted, so if you follow the older edge, you will reach the last value.
-func (t *Tree) Bottom() (string, interface{}, bool) {
+func (t *TypedTree[V]) Bottom() (string, V, bool) {
 	n := t.root
 	for {
 		if num := len(n.edges); num > 0 {
@@ -503,20 +588,64 @@ func (t *Tree) Bottom() (string, interface{}, bool) {
 		}
 		break
 	}
-	return "", nil, false
+	var zero V
+	return "", zero, false
 }
 
 // Callback function to pass when exploring the tree.
 // If true is returned, the tree search will stop at that point.
-type WalkCallback func(s string, v interface{}) bool
+type TypedWalkCallback[V any] func(s string, v V) bool
 
 // Follow the tree from the root node and execute the callback function when you find the leaf
-func (t *Tree) Walk(fn WalkCallback) {
+func (t *TypedTree[V]) Walk(fn TypedWalkCallback[V]) {
 	walk(t.root, fn)
 }
 
+// Same as Walk(), but in descending order
+func (t *TypedTree[V]) WalkReverse(fn TypedWalkCallback[V]) {
+	walkReverse(t.root, fn)
+}
+
+// Walk only the key-value pairs starting with a given prefix, same order as Walk()
+func (t *TypedTree[V]) WalkPrefix(prefix string, fn TypedWalkCallback[V]) {
+	found := t.prefixNode(prefix)
+	if found == nil {
+		return
+	}
+	walk(found, fn)
+}
+
+// Walk the key-value pairs whose key is a prefix of a given key, from the shortest to the longest.
+// These are the leafs that LongestMatch() passes through, the last one is the longest match.
+func (t *TypedTree[V]) WalkPath(key string, fn TypedWalkCallback[V]) {
+	searches := []rune(key)
+	n := t.root
+	for {
+		if n.isLeaf() {
+			if fn(n.leaf.key, n.leaf.value) {
+				return
+			}
+		}
+
+		if len(searches) == 0 {
+			return
+		}
+
+		n = n.getChild(searches[0])
+		if n == nil {
+			return
+		}
+
+		if startsWith(searches, n.prefixes) {
+			searches = searches[len(n.prefixes):]
+		} else {
+			return
+		}
+	}
+}
+
 // Call the callback function when the leaf is reached, and end the search when it returns true
-func walk(n *node, fn WalkCallback) bool {
+func walk[V any](n *node[V], fn TypedWalkCallback[V]) bool {
 	if n.leaf != nil {
 		if fn(n.leaf.key, n.leaf.value) {
 			return true
@@ -532,3 +661,43 @@ func walk(n *node, fn WalkCallback) bool {
 
 	return false
 }
+
+// Same as walk(), but in descending order.
+// The children are greater than n, so they are visited before n
+func walkReverse[V any](n *node[V], fn TypedWalkCallback[V]) bool {
+	for i := len(n.edges) - 1; i >= 0; i-- {
+		if walkReverse(n.edges[i].node, fn) {
+			return true
+		}
+	}
+
+	if n.leaf != nil {
+		if fn(n.leaf.key, n.leaf.value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Range() calls the callback function for each key-value pair where lo <= key < hi, in ascending order.
+// Iterator seeks lo by comparing with the prefixes of the nodes,
+// so the subtrees out of the range are never visited.
+func (t *TypedTree[V]) Range(lo, hi string, fn TypedWalkCallback[V]) {
+	it := t.Iterator()
+	for ok := it.SeekGE(lo); ok && it.Key() < hi; ok = it.Next() {
+		if fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// RangeReverse() is same as Range(), but in descending order.
+func (t *TypedTree[V]) RangeReverse(lo, hi string, fn TypedWalkCallback[V]) {
+	it := t.Iterator()
+	for ok := it.SeekLT(hi); ok && it.Key() >= lo; ok = it.Prev() {
+		if fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
@@ -242,6 +242,132 @@ func TestLongestMatch(t *testing.T) {
 	}
 }
 
+func TestTypedTree(t *testing.T) {
+	m := map[string]int{
+		"sea":    1,
+		"sells":  2,
+		"shells": 3,
+		"shore":  4,
+	}
+
+	r := NewTree[int]()
+	r.Load(m)
+
+	if r.Len() != len(m) {
+		t.Fatalf("expected length=%v, got=%v", len(m), r.Len())
+	}
+
+	// no type assertion is needed after lookup
+	for k, v := range m {
+		value, ok := r.Get(k)
+		if !ok {
+			t.Fatalf("key not found: %v", k)
+		}
+		if value != v {
+			t.Fatalf("expected=%v, got=%v", v, value)
+		}
+	}
+
+	// zero value is returned when the key is not found
+	value, ok := r.Get("shop")
+	if ok || value != 0 {
+		t.Fatalf("expected=%v, got=%v", 0, value)
+	}
+
+	if reflect.DeepEqual(r.ToMap(), m) == false {
+		t.Fatalf("expected=%v, got=%v", m, r.ToMap())
+	}
+
+	leafs := r.Collect("sh")
+	if len(leafs) != 2 || leafs[0].value != 3 || leafs[1].value != 4 {
+		t.Fatalf("unexpected leafs: %v", leafs)
+	}
+}
+
+func TestUntypedTree(t *testing.T) {
+	// the untyped names of the former API compile without the type parameter
+	var r *Tree = New()
+	r.Load(map[string]interface{}{"sea": 1, "sells": "two", "shells": 3.0})
+
+	keys := []string{}
+	var fn WalkCallback = func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return false
+	}
+	r.Walk(fn)
+	if fmt.Sprint(keys) != "[sea sells shells]" {
+		t.Fatalf("expected=%v, got=%v", "[sea sells shells]", keys)
+	}
+
+	var leafs []Leaf = r.Collect("se")
+	if len(leafs) != 2 || leafs[0].Key() != "sea" || leafs[1].Value() != "two" {
+		t.Fatalf("unexpected leafs: %v", leafs)
+	}
+
+	// Tree is the alias of TypedTree[any], not a distinct type
+	var typed *TypedTree[any] = r
+	if v, ok := typed.Get("shells"); !ok || v.(float64) != 3.0 {
+		t.Fatalf("expected=%v, got=%v", 3.0, v)
+	}
+}
+
+func TestPrefixMatches(t *testing.T) {
+	// routing table
+	routes := []struct {
+		prefix  string
+		gateway string
+	}{
+		{"10.0.0.0/8", "gig1"},
+		{"10.0.0.0/16", "gig2"},
+		{"10.0.0.0/24", "gig3"},
+		{"192.168.0.0/24", "gig4"},
+	}
+
+	r := NewTree[string]()
+
+	for _, route := range routes {
+		addr, masklen, err := cidrToBinaryString(route.prefix)
+		if err != nil {
+			t.Fatalf("failed to convert string: %v", route.prefix)
+		}
+		r.Insert(addr[:masklen], route.gateway)
+	}
+
+	tests := []struct {
+		destination string
+		expected    []string
+	}{
+		{"10.0.0.1", []string{"gig1", "gig2", "gig3"}},
+		{"10.0.1.1", []string{"gig1", "gig2"}},
+		{"10.1.1.1", []string{"gig1"}},
+		{"192.168.0.1", []string{"gig4"}},
+		{"172.16.0.1", []string{}},
+	}
+
+	for _, test := range tests {
+		addr, err := addrToBinaryString(test.destination)
+		if err != nil {
+			t.Fatal("failed to convert string", err)
+		}
+
+		got := []string{}
+		for _, leaf := range r.PrefixMatches(addr) {
+			got = append(got, leaf.value)
+		}
+		if reflect.DeepEqual(got, test.expected) == false {
+			t.Fatalf("destination: %v, expected: %v, got: %v", test.destination, test.expected, got)
+		}
+
+		_, v, found := r.ShortestMatch(addr)
+		if found != (len(test.expected) > 0) {
+			t.Fatalf("destination: %v, unexpected found=%v", test.destination, found)
+		}
+		if found && v != test.expected[0] {
+			t.Fatalf("destination: %v, expected: %v, got: %v", test.destination, test.expected[0], v)
+		}
+	}
+}
+
 func testUuid(t *testing.T) {
 	var min, max string
 	m := make(map[string]interface{})
@@ -457,3 +583,234 @@ func reverseUrl(s string) string {
 
 	return strings.Join(arr, ".")
 }
+
+//
+// go test -bench . -benchmem
+//
+
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = uuid()
+	}
+	return keys
+}
+
+func BenchmarkInsert(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewTree[int]()
+		for j, key := range keys {
+			r.Insert(key, j)
+		}
+	}
+}
+
+func BenchmarkByteTreeInsert(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewByteTree[int]()
+		for j, key := range keys {
+			r.Insert(key, j)
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	r := NewTree[int]()
+	for j, key := range keys {
+		r.Insert(key, j)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkByteTreeGet(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	r := NewByteTree[int]()
+	for j, key := range keys {
+		r.Insert(key, j)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkLongestMatch(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	r := NewTree[int]()
+	for j, key := range keys {
+		r.Insert(key[:len(key)/2], j)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.LongestMatch(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkByteTreeLongestMatch(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	r := NewByteTree[int]()
+	for j, key := range keys {
+		r.Insert(key[:len(key)/2], j)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.LongestMatch(keys[i%len(keys)])
+	}
+}
+
+func TestWalkReverse(t *testing.T) {
+	keys := []string{
+		"",
+		"あ",
+		"あい",
+		"あいう",
+		"あかさたな",
+		"い",
+	}
+
+	r := New()
+	for _, key := range keys {
+		r.Insert(key, nil)
+	}
+
+	got := []string{}
+	r.WalkReverse(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+
+	expected := []string{"い", "あかさたな", "あいう", "あい", "あ", ""}
+	if reflect.DeepEqual(got, expected) == false {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+}
+
+func TestWalkPrefix(t *testing.T) {
+	keys := []string{
+		"sea",
+		"sells",
+		"shells",
+		"shore",
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"s", []string{"sea", "sells", "shells", "shore"}},
+		{"sh", []string{"shells", "shore"}},
+		{"she", []string{"shells"}},
+		{"shop", []string{}},
+	}
+
+	r := New()
+	for _, key := range keys {
+		r.Insert(key, nil)
+	}
+
+	for _, test := range tests {
+		got := []string{}
+		r.WalkPrefix(test.input, func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		if reflect.DeepEqual(got, test.expected) == false {
+			t.Fatalf("input: %v, expected: %v, got: %v", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestWalkPath(t *testing.T) {
+	keys := []string{
+		"",
+		"あ",
+		"い",
+		"あい",
+		"あいう",
+		"あいうえお",
+		"あかさたな",
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a", []string{""}},
+		{"あいかわ", []string{"", "あ", "あい"}},
+		{"あいうえおかきくけこ", []string{"", "あ", "あい", "あいう", "あいうえお"}},
+		{"あか", []string{"", "あ"}},
+	}
+
+	r := New()
+	for _, key := range keys {
+		r.Insert(key, nil)
+	}
+
+	for _, test := range tests {
+		got := []string{}
+		r.WalkPath(test.input, func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		if reflect.DeepEqual(got, test.expected) == false {
+			t.Fatalf("input: %v, expected: %v, got: %v", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestCollectValues(t *testing.T) {
+	m := map[string]int{
+		"sea":    1,
+		"sells":  2,
+		"shells": 3,
+		"shore":  4,
+	}
+
+	r := NewTree[int]()
+	r.Load(m)
+
+	// exported accessors of the Leaf
+	for _, leaf := range r.Collect("s") {
+		if m[leaf.Key()] != leaf.Value() {
+			t.Fatalf("key=%v, expected=%v, got=%v", leaf.Key(), m[leaf.Key()], leaf.Value())
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"s", []int{1, 2, 3, 4}},
+		{"sh", []int{3, 4}},
+		{"shop", []int{}},
+	}
+
+	for _, test := range tests {
+		values := r.CollectValues(test.input)
+		if reflect.DeepEqual(values, test.expected) == false {
+			t.Fatalf("input: %v, expected: %v, got: %v", test.input, test.expected, values)
+		}
+	}
+
+	if got := r.CollectMap("se"); reflect.DeepEqual(got, map[string]int{"sea": 1, "sells": 2}) == false {
+		t.Fatalf("unexpected map: %v", got)
+	}
+
+	if got := r.CollectMap("shop"); len(got) != 0 {
+		t.Fatalf("unexpected map: %v", got)
+	}
+}
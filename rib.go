@@ -0,0 +1,189 @@
+package radix
+
+import (
+	"net/netip"
+	"sort"
+)
+
+//
+// RIB (Routing Information Base) keeps several routes per prefix learned from the different protocols.
+// The routes of a prefix are ordered by the preference,
+// the lower administrative distance wins, then the lower metric wins.
+// The routes with the same distance and metric are all used as ECMP (Equal Cost Multi Path).
+//
+//   10.0.0.0/8  static 1/0   via 192.168.0.1   <- best
+//               ospf   110/20 via 192.168.1.1
+//               ospf   110/20 via 192.168.1.2
+//
+// When the static route is removed, the two ospf routes become the best routes.
+// A route is identified by its prefix, protocol and next hop,
+// adding the route with the same identity replaces the old one.
+//
+
+// Route definition
+type Route struct {
+	Prefix   netip.Prefix
+	NextHop  netip.Addr
+	Protocol string // source of the route, "connected", "static", "ospf", "bgp" and so on
+	Distance uint8  // administrative distance
+	Metric   uint32
+}
+
+// returns true if r and other are the same route, the attributes are not compared
+func (r Route) same(other Route) bool {
+	return r.Protocol == other.Protocol && r.NextHop == other.NextHop
+}
+
+// returns true if r is preferred to other
+func (r Route) less(other Route) bool {
+	if r.Distance != other.Distance {
+		return r.Distance < other.Distance
+	}
+	if r.Metric != other.Metric {
+		return r.Metric < other.Metric
+	}
+	// the order of equal cost routes is fixed to make the results stable
+	if r.Protocol != other.Protocol {
+		return r.Protocol < other.Protocol
+	}
+	return r.NextHop.Less(other.NextHop)
+}
+
+// RIB definition
+type RIB struct {
+	table *PrefixTable[[]Route] // routes of each prefix, sorted by preference
+	size  int                   // number of routes
+}
+
+// Constructor
+// NewRIB() returns empty RIB
+func NewRIB() *RIB {
+	return &RIB{
+		table: NewPrefixTable[[]Route](),
+		size:  0,
+	}
+}
+
+// Len() returns number of routes stored in the RIB
+func (r *RIB) Len() int {
+	return r.size
+}
+
+// Prefixes() returns number of prefixes which have at least one route
+func (r *RIB) Prefixes() int {
+	return r.table.Len()
+}
+
+// Add a route to the RIB.
+// The host bits of the prefix are ignored.
+// returns true if newly added.
+// returns false if replace the route with the same protocol and next hop, or the prefix is invalid.
+func (r *RIB) Add(route Route) bool {
+	if !route.Prefix.IsValid() {
+		return false
+	}
+	route.Prefix = route.Prefix.Masked()
+
+	// the slice is always copied, so the slices returned to the caller are never modified
+	old, _ := r.table.Get(route.Prefix)
+	routes := make([]Route, 0, len(old)+1)
+	added := true
+	for _, o := range old {
+		if o.same(route) {
+			added = false
+			continue
+		}
+		routes = append(routes, o)
+	}
+	routes = append(routes, route)
+	sort.Slice(routes, func(i, j int) bool { return routes[i].less(routes[j]) })
+
+	r.table.Insert(route.Prefix, routes)
+	if added {
+		r.size++
+	}
+	return added
+}
+
+// Remove the route which has the same prefix, protocol and next hop as the given route.
+// returns true if removed.
+func (r *RIB) Remove(route Route) bool {
+	return r.removeFunc(route.Prefix, route.same) > 0
+}
+
+// RemoveProtocol() removes all routes of the prefix learned from the protocol,
+// and returns number of the removed routes.
+func (r *RIB) RemoveProtocol(prefix netip.Prefix, protocol string) int {
+	return r.removeFunc(prefix, func(o Route) bool { return o.Protocol == protocol })
+}
+
+// removes the routes of the prefix which match the function, and returns number of removed routes
+func (r *RIB) removeFunc(prefix netip.Prefix, match func(Route) bool) int {
+	if !prefix.IsValid() {
+		return 0
+	}
+	prefix = prefix.Masked()
+
+	old, ok := r.table.Get(prefix)
+	if !ok {
+		return 0
+	}
+	routes := make([]Route, 0, len(old))
+	for _, o := range old {
+		if !match(o) {
+			routes = append(routes, o)
+		}
+	}
+
+	removed := len(old) - len(routes)
+	if removed == 0 {
+		return 0
+	}
+	if len(routes) == 0 {
+		r.table.Delete(prefix)
+	} else {
+		r.table.Insert(prefix, routes)
+	}
+	r.size -= removed
+	return removed
+}
+
+// Routes() returns all routes of the prefix, the preferred one comes first.
+// The returned slice must not be modified.
+func (r *RIB) Routes(prefix netip.Prefix) []Route {
+	routes, _ := r.table.Get(prefix.Masked())
+	return routes
+}
+
+// Best() returns the best routes of the prefix, more than one if ECMP.
+// The returned slice must not be modified.
+func (r *RIB) Best(prefix netip.Prefix) []Route {
+	return bestRoutes(r.Routes(prefix))
+}
+
+// Lookup() returns the best routes of the longest prefix which contains the address.
+// If no route found, returns nil.
+// The returned slice must not be modified.
+func (r *RIB) Lookup(addr netip.Addr) []Route {
+	_, routes, _ := r.table.Lookup(addr)
+	return bestRoutes(routes)
+}
+
+// Walk() calls the callback function with the best routes of each prefix, in the same order as PrefixTable.Walk()
+func (r *RIB) Walk(fn func(prefix netip.Prefix, best []Route) bool) {
+	r.table.Walk(func(p netip.Prefix, routes []Route) bool {
+		return fn(p, bestRoutes(routes))
+	})
+}
+
+// returns the leading routes which have the same distance and metric as the first one
+func bestRoutes(routes []Route) []Route {
+	if len(routes) == 0 {
+		return nil
+	}
+	n := 1
+	for n < len(routes) && routes[n].Distance == routes[0].Distance && routes[n].Metric == routes[0].Metric {
+		n++
+	}
+	return routes[:n:n]
+}
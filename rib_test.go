@@ -0,0 +1,100 @@
+package radix
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestRIB(t *testing.T) {
+	p := netip.MustParsePrefix
+	a := netip.MustParseAddr
+
+	static := Route{Prefix: p("10.0.0.0/8"), NextHop: a("192.168.0.1"), Protocol: "static", Distance: 1}
+	ospf1 := Route{Prefix: p("10.0.0.0/8"), NextHop: a("192.168.1.1"), Protocol: "ospf", Distance: 110, Metric: 20}
+	ospf2 := Route{Prefix: p("10.0.0.0/8"), NextHop: a("192.168.1.2"), Protocol: "ospf", Distance: 110, Metric: 20}
+	ospf3 := Route{Prefix: p("10.0.0.0/8"), NextHop: a("192.168.1.3"), Protocol: "ospf", Distance: 110, Metric: 30}
+	bgp := Route{Prefix: p("10.1.0.0/16"), NextHop: a("172.16.0.1"), Protocol: "bgp", Distance: 20}
+	def := Route{Prefix: p("0.0.0.0/0"), NextHop: a("192.168.0.254"), Protocol: "static", Distance: 1}
+
+	rib := NewRIB()
+	for _, route := range []Route{ospf3, ospf2, static, ospf1, bgp, def} {
+		if !rib.Add(route) {
+			t.Fatalf("failed to add %v", route)
+		}
+	}
+	if rib.Len() != 6 || rib.Prefixes() != 3 {
+		t.Fatalf("expected=6/3, got=%v/%v", rib.Len(), rib.Prefixes())
+	}
+
+	// all routes are sorted by preference
+	expected := []Route{static, ospf1, ospf2, ospf3}
+	if got := rib.Routes(p("10.0.0.0/8")); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	tests := []struct {
+		addr     string
+		expected []Route
+	}{
+		{"10.0.0.1", []Route{static}},
+		{"10.1.0.1", []Route{bgp}},
+		{"8.8.8.8", []Route{def}},
+		{"2001:db8::1", nil},
+	}
+	for _, tt := range tests {
+		if got := rib.Lookup(a(tt.addr)); !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("addr=%v, expected=%v, got=%v", tt.addr, tt.expected, got)
+		}
+	}
+
+	// removing the static route reveals ECMP ospf routes
+	if !rib.Remove(static) {
+		t.Fatalf("failed to remove %v", static)
+	}
+	if rib.Remove(static) {
+		t.Fatalf("removed twice %v", static)
+	}
+	expected = []Route{ospf1, ospf2}
+	if got := rib.Lookup(a("10.0.0.1")); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	// replace the metric of ospf1, then ospf2 is the only best route
+	worse := ospf1
+	worse.Metric = 40
+	if rib.Add(worse) {
+		t.Fatalf("expected replace, got add")
+	}
+	expected = []Route{ospf2, ospf3, worse}
+	if got := rib.Routes(p("10.0.0.0/8")); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected=%v, got=%v", expected, got)
+	}
+
+	// the slice returned before is not affected by the later changes
+	best := rib.Best(p("10.0.0.0/8"))
+
+	// removing all ospf routes removes the prefix, and the default route is used
+	if n := rib.RemoveProtocol(p("10.0.0.0/8"), "ospf"); n != 3 {
+		t.Fatalf("expected=3, got=%v", n)
+	}
+	if got := rib.Lookup(a("10.0.0.1")); !reflect.DeepEqual(got, []Route{def}) {
+		t.Fatalf("expected=%v, got=%v", []Route{def}, got)
+	}
+	if rib.Len() != 2 || rib.Prefixes() != 2 {
+		t.Fatalf("expected=2/2, got=%v/%v", rib.Len(), rib.Prefixes())
+	}
+	if !reflect.DeepEqual(best, []Route{ospf2}) {
+		t.Fatalf("expected=%v, got=%v", []Route{ospf2}, best)
+	}
+
+	// walk
+	prefixes := []netip.Prefix{}
+	rib.Walk(func(prefix netip.Prefix, best []Route) bool {
+		prefixes = append(prefixes, prefix)
+		return false
+	})
+	if !reflect.DeepEqual(prefixes, parsePrefixes("0.0.0.0/0", "10.1.0.0/16")) {
+		t.Fatalf("unexpected walk %v", prefixes)
+	}
+}
@@ -0,0 +1,232 @@
+package radix
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+//
+// Router is the HTTP request router which matches the URL path segment by segment.
+// Each node has the static children in the byte radix tree keyed by the segment,
+// the parameter child and the catch-all child.
+//
+//   /users/:id          matches /users/123, r.PathValue("id") is "123"
+//   /users/:id/posts    matches /users/123/posts
+//   /users/new          matches /users/new, static segment is preferred to the parameter
+//   /static/*path       matches /static/css/main.css, r.PathValue("path") is "css/main.css"
+//
+// The static segment is tried first, then the parameter, then the catch-all.
+// If the preferred one does not lead to a route, the next one is tried.
+//
+// If no route matches, it responds 404 Not Found.
+// If the route matches but the method does not, it responds 405 Method Not Allowed with the Allow header.
+// HEAD requests are handled by the GET handler unless the HEAD handler is registered.
+//
+
+// routeNode definition
+type routeNode struct {
+	static    *ByteTree[*routeNode] // children keyed by the static segment, exact bytes even if not UTF-8
+	param     *routeNode            // child for ":name" segment
+	paramName string
+	catchAll  *routeNode // child for "*name" segment, always the last segment
+	catchName string
+	pattern   string                  // registered pattern of the route
+	params    []string                // names of the parameters in the pattern
+	handlers  map[string]http.Handler // handlers by method
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{
+		static: NewByteTree[*routeNode](),
+	}
+}
+
+// Router definition
+type Router struct {
+	root     *routeNode
+	NotFound http.Handler // handler for 404, http.NotFound if nil
+}
+
+// Constructor
+// NewRouter() returns empty Router
+func NewRouter() *Router {
+	return &Router{
+		root: newRouteNode(),
+	}
+}
+
+// returns the segments of the path, "/users/123" => ["users", "123"]
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+// Handle() registers the handler for the method and the pattern.
+// The pattern starts with '/', and its segments are static, ":name" or "*name".
+// returns error if the pattern is invalid, conflicts with the registered patterns,
+// or the method and the pattern are already registered.
+func (rt *Router) Handle(method, pattern string, h http.Handler) error {
+	if method == "" {
+		return fmt.Errorf("radix: empty method for %q", pattern)
+	}
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("radix: pattern %q must start with '/'", pattern)
+	}
+
+	n := rt.root
+	params := []string{}
+	segments := splitPath(pattern)
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name := seg[1:]
+			if name == "" {
+				return fmt.Errorf("radix: empty parameter name in %q", pattern)
+			}
+			if n.param == nil {
+				n.param, n.paramName = newRouteNode(), name
+			} else if n.paramName != name {
+				return fmt.Errorf("radix: parameter %q in %q conflicts with %q", name, pattern, n.paramName)
+			}
+			params = append(params, name)
+			n = n.param
+
+		case strings.HasPrefix(seg, "*"):
+			name := seg[1:]
+			if name == "" {
+				return fmt.Errorf("radix: empty catch-all name in %q", pattern)
+			}
+			if i != len(segments)-1 {
+				return fmt.Errorf("radix: catch-all must be the last segment in %q", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll, n.catchName = newRouteNode(), name
+			} else if n.catchName != name {
+				return fmt.Errorf("radix: catch-all %q in %q conflicts with %q", name, pattern, n.catchName)
+			}
+			params = append(params, name)
+			n = n.catchAll
+
+		default:
+			child, ok := n.static.Get(seg)
+			if !ok {
+				child = newRouteNode()
+				n.static.Insert(seg, child)
+			}
+			n = child
+		}
+	}
+
+	if _, ok := n.handlers[method]; ok {
+		return fmt.Errorf("radix: %s %s is already registered", method, pattern)
+	}
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+	}
+	n.handlers[method] = h
+	n.pattern = pattern
+	n.params = params
+	return nil
+}
+
+// HandleFunc() registers the handler function for the method and the pattern
+func (rt *Router) HandleFunc(method, pattern string, h func(http.ResponseWriter, *http.Request)) error {
+	return rt.Handle(method, pattern, http.HandlerFunc(h))
+}
+
+// returns the node of the route which matches the segments, and the values of the parameters
+func (n *routeNode) match(segments []string, values []string) (*routeNode, []string) {
+	if len(segments) == 0 {
+		if len(n.handlers) > 0 {
+			return n, values
+		}
+		return nil, nil
+	}
+
+	seg := segments[0]
+	if child, ok := n.static.Get(seg); ok {
+		if found, v := child.match(segments[1:], values); found != nil {
+			return found, v
+		}
+	}
+	if n.param != nil && seg != "" {
+		if found, v := n.param.match(segments[1:], append(values, seg)); found != nil {
+			return found, v
+		}
+	}
+	if n.catchAll != nil && len(n.catchAll.handlers) > 0 {
+		return n.catchAll, append(values, strings.Join(segments, "/"))
+	}
+	return nil, nil
+}
+
+// returns the methods of the route for the Allow header
+func (n *routeNode) allowed() string {
+	methods := make([]string, 0, len(n.handlers)+1)
+	for method := range n.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+// Match() returns the pattern of the route which matches the path, and the values of the parameters.
+// If no route matches, returns false.
+func (rt *Router) Match(path string) (string, map[string]string, bool) {
+	n, values := rt.root.match(splitPath(path), nil)
+	if n == nil {
+		return "", nil, false
+	}
+	params := make(map[string]string, len(n.params))
+	for i, name := range n.params {
+		params[name] = values[i]
+	}
+	return n.pattern, params, true
+}
+
+// ServeHTTP() dispatches the request to the handler of the matched route.
+// The parameters are set to the request, use r.PathValue() to get them.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// split the escaped path, so that "%2F" in the segment is not a separator
+	segments := splitPath(r.URL.EscapedPath())
+	for i, seg := range segments {
+		unescaped, err := url.PathUnescape(seg)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		segments[i] = unescaped
+	}
+
+	n, values := rt.root.match(segments, nil)
+	if n == nil {
+		if rt.NotFound != nil {
+			rt.NotFound.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	h, ok := n.handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		h, ok = n.handlers[http.MethodGet]
+	}
+	if !ok {
+		w.Header().Set("Allow", n.allowed())
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	for i, name := range n.params {
+		r.SetPathValue(name, values[i])
+	}
+	h.ServeHTTP(w, r)
+}
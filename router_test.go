@@ -0,0 +1,161 @@
+package radix
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	rt := NewRouter()
+
+	// each handler writes the route and its parameters
+	handler := func(route string, params ...string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, route)
+			for _, name := range params {
+				fmt.Fprintf(w, " %s=%s", name, r.PathValue(name))
+			}
+		}
+	}
+
+	routes := []struct {
+		method  string
+		pattern string
+		params  []string
+	}{
+		{"GET", "/", nil},
+		{"GET", "/users", nil},
+		{"POST", "/users", nil},
+		{"GET", "/users/new", nil},
+		{"GET", "/users/:id", []string{"id"}},
+		{"DELETE", "/users/:id", []string{"id"}},
+		{"GET", "/users/:id/posts/:post", []string{"id", "post"}},
+		{"GET", "/users/new/posts/latest", nil},
+		{"GET", "/static/*path", []string{"path"}},
+		{"GET", "/static/favicon.ico", nil},
+		{"GET", "/files/:dir/*rest", []string{"dir", "rest"}},
+	}
+	for _, route := range routes {
+		if err := rt.Handle(route.method, route.pattern, handler(route.method+" "+route.pattern, route.params...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+		allow  string
+	}{
+		{"GET", "/", 200, "GET /", ""},
+		{"GET", "/users", 200, "GET /users", ""},
+		{"POST", "/users", 200, "POST /users", ""},
+		{"GET", "/users/", 404, "404 page not found\n", ""},
+		{"GET", "/users/new", 200, "GET /users/new", ""},
+		{"GET", "/users/123", 200, "GET /users/:id id=123", ""},
+		{"DELETE", "/users/123", 200, "DELETE /users/:id id=123", ""},
+		{"HEAD", "/users/123", 200, "GET /users/:id id=123", ""}, // the recorder keeps the body of HEAD
+		{"PUT", "/users/123", 405, "Method Not Allowed\n", "DELETE, GET, HEAD"},
+		{"DELETE", "/users/new", 405, "Method Not Allowed\n", "GET, HEAD"},
+		{"GET", "/users/123/posts/9", 200, "GET /users/:id/posts/:post id=123 post=9", ""},
+		// static "new" does not lead to a route, then the parameter is tried
+		{"GET", "/users/new/posts/9", 200, "GET /users/:id/posts/:post id=new post=9", ""},
+		{"GET", "/users/new/posts/latest", 200, "GET /users/new/posts/latest", ""},
+		{"GET", "/users/a%2Fb", 200, "GET /users/:id id=a/b", ""},
+		{"GET", "/users/123/comments", 404, "404 page not found\n", ""},
+		{"GET", "/static/css/main.css", 200, "GET /static/*path path=css/main.css", ""},
+		{"GET", "/static/", 200, "GET /static/*path path=", ""},
+		{"GET", "/static", 404, "404 page not found\n", ""},
+		{"GET", "/static/favicon.ico", 200, "GET /static/favicon.ico", ""},
+		{"GET", "/files/docs/a/b.txt", 200, "GET /files/:dir/*rest dir=docs rest=a/b.txt", ""},
+		{"GET", "/nothing", 404, "404 page not found\n", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, req)
+
+		res := w.Result()
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != tt.status || string(body) != tt.body || res.Header.Get("Allow") != tt.allow {
+			t.Fatalf("%v %v, expected=%v %q %q, got=%v %q %q", tt.method, tt.path, tt.status, tt.body, tt.allow, res.StatusCode, body, res.Header.Get("Allow"))
+		}
+	}
+
+	pattern, params, ok := rt.Match("/users/42/posts/7")
+	if !ok || pattern != "/users/:id/posts/:post" || !reflect.DeepEqual(params, map[string]string{"id": "42", "post": "7"}) {
+		t.Fatalf("unexpected match %v %v %v", pattern, params, ok)
+	}
+
+	// custom 404 handler
+	rt.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/nothing", nil))
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected=%v, got=%v", http.StatusTeapot, w.Code)
+	}
+
+	invalids := []struct {
+		method  string
+		pattern string
+	}{
+		{"GET", "/users"},             // duplicated
+		{"GET", "users"},              // no leading slash
+		{"", "/empty/method"},         // no method
+		{"GET", "/users/:name/books"}, // conflict parameter name
+		{"GET", "/static/*file"},      // conflict catch-all name
+		{"GET", "/a/*rest/b"},         // catch-all is not the last
+		{"GET", "/a/:"},               // empty parameter name
+		{"GET", "/a/*"},               // empty catch-all name
+	}
+	for _, tt := range invalids {
+		if err := rt.HandleFunc(tt.method, tt.pattern, handler("")); err == nil {
+			t.Fatalf("expected error, %v %v", tt.method, tt.pattern)
+		}
+	}
+}
+
+func TestRouterInvalidUTF8(t *testing.T) {
+	rt := NewRouter()
+	for _, pattern := range []string{"/admin/\xff", "/admin/�"} {
+		p := pattern
+		if err := rt.HandleFunc("GET", p, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%q", p)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the segments which are not valid UTF-8 must not be replaced with U+FFFD
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/admin/%FF", 200, `"/admin/\xff"`},
+		{"/admin/%EF%BF%BD", 200, `"/admin/�"`},
+		{"/admin/%FE", 404, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Fatalf("%v, expected=%v %q, got=%v %q", tt.path, tt.status, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	if pattern, _, ok := rt.Match("/admin/\xfe"); ok {
+		t.Fatalf("expected no match, got=%q", pattern)
+	}
+	if pattern, _, ok := rt.Match("/admin/\xff"); !ok || pattern != "/admin/\xff" {
+		t.Fatalf("expected=%q, got=%q %v", "/admin/\xff", pattern, ok)
+	}
+}
@@ -0,0 +1,277 @@
+package radix
+
+import (
+	"iter"
+	"sync"
+)
+
+//
+// SyncTree wraps Tree and guards all of its methods with a reader/writer lock,
+// so it can be shared by many goroutines.
+// Lookups such as Get(), LongestMatch() and PrefixMatches() take the read lock and run in parallel,
+// Insert() and Delete() take the write lock.
+//
+// Walk() and the other walk methods do not hold the lock while the callback function is running.
+// It takes a snapshot of the key-value pairs under the read lock, then calls the callback for each of them.
+// So the callback function may call any method of the SyncTree including Insert() and Delete(),
+// but the changes made during the walk are not visible to that walk.
+// The iterators such as All() work in the same way, the snapshot is taken when the loop starts.
+//
+
+// SyncTree definition
+type SyncTree[V any] struct {
+	mu   sync.RWMutex
+	tree *TypedTree[V]
+}
+
+// Constructor
+// NewSyncTree() returns empty SyncTree instance which stores values of type V
+func NewSyncTree[V any]() *SyncTree[V] {
+	return &SyncTree[V]{
+		tree: NewTree[V](),
+	}
+}
+
+// Len() returns number of key-value-pairs stored in the SyncTree
+func (t *SyncTree[V]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Len()
+}
+
+// Load() receive a map and store its contents in the SyncTree
+func (t *SyncTree[V]) Load(m map[string]V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Load(m)
+}
+
+// ToMap() is reverse of Load()
+func (t *SyncTree[V]) ToMap() map[string]V {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.ToMap()
+}
+
+// Add a new key-value pair to the tree.
+// returns true if newly inserted, false if update existing key-value pair.
+func (t *SyncTree[V]) Insert(k string, v V) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Insert(k, v)
+}
+
+// Delete key-value pair and returns its value and true.
+// If key not found, returns zero value and false.
+func (t *SyncTree[V]) Delete(key string) (V, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Delete(key)
+}
+
+// If there is a key-value pair corresponding to given key, it will be returned,
+// otherwise zero value and false will be returned.
+func (t *SyncTree[V]) Get(key string) (V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Get(key)
+}
+
+// Returns the closest key-value pair in a longest match rule
+func (t *SyncTree[V]) LongestMatch(key string) (string, V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.LongestMatch(key)
+}
+
+// Returns the closest key-value pair in a shortest match rule
+func (t *SyncTree[V]) ShortestMatch(key string) (string, V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.ShortestMatch(key)
+}
+
+// Returns all key-value pairs whose key is a prefix of a given key, from the shortest to the longest
+func (t *SyncTree[V]) PrefixMatches(key string) []TypedLeaf[V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.PrefixMatches(key)
+}
+
+// Find all key-values starting with a given key
+func (t *SyncTree[V]) Collect(key string) []TypedLeaf[V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Collect(key)
+}
+
+func (t *SyncTree[V]) CollectKeys(key string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.CollectKeys(key)
+}
+
+func (t *SyncTree[V]) CollectValues(key string) []V {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.CollectValues(key)
+}
+
+func (t *SyncTree[V]) CollectMap(key string) map[string]V {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.CollectMap(key)
+}
+
+// Find all key-values whose key matches the glob pattern
+func (t *SyncTree[V]) Match(pattern string) ([]TypedLeaf[V], error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Match(pattern)
+}
+
+func (t *SyncTree[V]) MatchKeys(pattern string) ([]string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.MatchKeys(pattern)
+}
+
+// Returns the first key-value pair of the tree
+func (t *SyncTree[V]) Top() (string, V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Top()
+}
+
+// Returns the last key-value pair of the tree
+func (t *SyncTree[V]) Bottom() (string, V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Bottom()
+}
+
+// Walk() calls the callback function for the snapshot of the key-value pairs taken when it is called.
+// The lock is not held while the callback function is running.
+func (t *SyncTree[V]) Walk(fn TypedWalkCallback[V]) {
+	leafs := t.Collect("")
+	walkLeafs(leafs, fn)
+}
+
+// Same as Walk(), but in descending order
+func (t *SyncTree[V]) WalkReverse(fn TypedWalkCallback[V]) {
+	leafs := t.snapshot(func(collect TypedWalkCallback[V]) {
+		t.tree.WalkReverse(collect)
+	})
+	walkLeafs(leafs, fn)
+}
+
+// Walk only the key-value pairs starting with a given prefix, same order as Walk()
+func (t *SyncTree[V]) WalkPrefix(prefix string, fn TypedWalkCallback[V]) {
+	leafs := t.snapshot(func(collect TypedWalkCallback[V]) {
+		t.tree.WalkPrefix(prefix, collect)
+	})
+	walkLeafs(leafs, fn)
+}
+
+// Walk the key-value pairs whose key is a prefix of a given key, from the shortest to the longest
+func (t *SyncTree[V]) WalkPath(key string, fn TypedWalkCallback[V]) {
+	leafs := t.snapshot(func(collect TypedWalkCallback[V]) {
+		t.tree.WalkPath(key, collect)
+	})
+	walkLeafs(leafs, fn)
+}
+
+// Range() calls the callback function for the snapshot of the key-value pairs where lo <= key < hi, in ascending order
+func (t *SyncTree[V]) Range(lo, hi string, fn TypedWalkCallback[V]) {
+	leafs := t.snapshot(func(collect TypedWalkCallback[V]) {
+		t.tree.Range(lo, hi, collect)
+	})
+	walkLeafs(leafs, fn)
+}
+
+// RangeReverse() is same as Range(), but in descending order.
+func (t *SyncTree[V]) RangeReverse(lo, hi string, fn TypedWalkCallback[V]) {
+	leafs := t.snapshot(func(collect TypedWalkCallback[V]) {
+		t.tree.RangeReverse(lo, hi, collect)
+	})
+	walkLeafs(leafs, fn)
+}
+
+// All() returns an iterator over the snapshot of all key-value pairs in ascending order
+func (t *SyncTree[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.Walk(func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Backward() returns an iterator over the snapshot of all key-value pairs in descending order
+func (t *SyncTree[V]) Backward() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.WalkReverse(func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// WithPrefix() returns an iterator over the snapshot of the key-value pairs starting with a given prefix
+func (t *SyncTree[V]) WithPrefix(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.WalkPrefix(prefix, func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Between() returns an iterator over the snapshot of the key-value pairs where lo <= key < hi
+func (t *SyncTree[V]) Between(lo, hi string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.Range(lo, hi, func(k string, v V) bool {
+			return !yield(k, v)
+		})
+	}
+}
+
+// Keys() returns an iterator over the snapshot of all keys in ascending order
+func (t *SyncTree[V]) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, k := range t.CollectKeys("") {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values() returns an iterator over the snapshot of all values in ascending order of the keys
+func (t *SyncTree[V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range t.CollectValues("") {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// returns the key-value pairs visited by the walk function under the read lock
+func (t *SyncTree[V]) snapshot(walkFn func(collect TypedWalkCallback[V])) []TypedLeaf[V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	leafs := []TypedLeaf[V]{}
+	walkFn(func(k string, v V) bool {
+		leafs = append(leafs, TypedLeaf[V]{key: k, value: v})
+		return false
+	})
+	return leafs
+}
+
+// calls the callback function for each leaf until it returns true
+func walkLeafs[V any](leafs []TypedLeaf[V], fn TypedWalkCallback[V]) {
+	for _, leaf := range leafs {
+		if fn(leaf.key, leaf.value) {
+			return
+		}
+	}
+}
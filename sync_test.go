@@ -0,0 +1,154 @@
+package radix
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+//
+// go test -race -run Sync
+//
+
+func TestSyncTreeConcurrent(t *testing.T) {
+	r := NewSyncTree[int]()
+
+	const writers = 4
+	const readers = 8
+	const keys = 500
+
+	var wg sync.WaitGroup
+
+	// writers insert and delete their own keys
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < keys; i++ {
+				r.Insert(fmt.Sprintf("%d/%d", w, i), i)
+			}
+			for i := 0; i < keys; i += 2 {
+				if _, ok := r.Delete(fmt.Sprintf("%d/%d", w, i)); !ok {
+					t.Errorf("delete failed %d/%d", w, i)
+				}
+			}
+		}(w)
+	}
+
+	// readers look up while the writers are running
+	for rd := 0; rd < readers; rd++ {
+		wg.Add(1)
+		go func(rd int) {
+			defer wg.Done()
+			for i := 0; i < keys; i++ {
+				key := fmt.Sprintf("%d/%d", rd%writers, i)
+				if v, ok := r.Get(key); ok && v != i {
+					t.Errorf("key=%v, expected=%v, got=%v", key, i, v)
+				}
+				r.LongestMatch(key + "/x")
+				r.ShortestMatch(key + "/x")
+				r.PrefixMatches(key + "/x")
+				r.Collect(fmt.Sprintf("%d/", rd%writers))
+				r.Len()
+			}
+			r.Walk(func(k string, v int) bool {
+				return false
+			})
+		}(rd)
+	}
+
+	wg.Wait()
+
+	if r.Len() != writers*keys/2 {
+		t.Fatalf("expected length=%v, got=%v", writers*keys/2, r.Len())
+	}
+}
+
+func TestSyncTreeMutateDuringWalk(t *testing.T) {
+	r := NewSyncTree[int]()
+	for i := 0; i < 10; i++ {
+		r.Insert(fmt.Sprintf("key%d", i), i)
+	}
+
+	// the callback may mutate the tree, the walk continues on the snapshot
+	count := 0
+	r.Walk(func(k string, v int) bool {
+		count++
+		r.Delete(k)
+		r.Insert(k+"/new", v)
+		return false
+	})
+
+	if count != 10 {
+		t.Fatalf("expected count=%v, got=%v", 10, count)
+	}
+
+	if r.Len() != 10 {
+		t.Fatalf("expected length=%v, got=%v", 10, r.Len())
+	}
+
+	if keys := r.CollectKeys("key0"); len(keys) != 1 || keys[0] != "key0/new" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+}
+
+func TestSyncTreePrefixMatches(t *testing.T) {
+	r := NewSyncTree[int]()
+	r.Load(map[string]int{"10.": 1, "10.0.": 2, "10.0.0.": 3, "192.": 4})
+
+	k, v, ok := r.ShortestMatch("10.0.0.1")
+	if !ok || k != "10." || v != 1 {
+		t.Fatalf("expected=%v %v, got=%v %v %v", "10.", 1, k, v, ok)
+	}
+
+	keys := []string{}
+	for _, leaf := range r.PrefixMatches("10.0.0.1") {
+		keys = append(keys, leaf.key)
+	}
+	if fmt.Sprint(keys) != "[10. 10.0. 10.0.0.]" {
+		t.Fatalf("expected=%v, got=%v", "[10. 10.0. 10.0.0.]", keys)
+	}
+}
+
+func TestSyncTreeWalkVariants(t *testing.T) {
+	r := NewSyncTree[int]()
+	r.Load(map[string]int{"a": 1, "ab": 2, "abc": 3, "b": 4})
+
+	// the callback returns the keys, and mutates the tree which must not deadlock
+	collect := func(walk func(TypedWalkCallback[int])) []string {
+		keys := []string{}
+		walk(func(k string, v int) bool {
+			keys = append(keys, k)
+			r.Insert(k+"/new", v)
+			r.Delete(k + "/new")
+			return false
+		})
+		return keys
+	}
+
+	tests := []struct {
+		keys     []string
+		expected string
+	}{
+		{collect(r.WalkReverse), "[b abc ab a]"},
+		{collect(func(fn TypedWalkCallback[int]) { r.WalkPrefix("ab", fn) }), "[ab abc]"},
+		{collect(func(fn TypedWalkCallback[int]) { r.WalkPath("abcd", fn) }), "[a ab abc]"},
+		{collect(func(fn TypedWalkCallback[int]) { r.Range("ab", "b", fn) }), "[ab abc]"},
+		{collect(func(fn TypedWalkCallback[int]) { r.RangeReverse("a", "abc", fn) }), "[ab a]"},
+	}
+	for _, tt := range tests {
+		if fmt.Sprint(tt.keys) != tt.expected {
+			t.Fatalf("expected=%v, got=%v", tt.expected, tt.keys)
+		}
+	}
+
+	// stop the walk
+	count := 0
+	r.WalkReverse(func(k string, v int) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Fatalf("expected count=%v, got=%v", 1, count)
+	}
+}